@@ -0,0 +1,55 @@
+// Compares restart rule matching with the compiled-matcher cache against
+// compiling every pattern per file, on a module tree with thousands of files.
+// Run with `npm run bench`.
+import { performance } from 'node:perf_hooks';
+import micromatch from 'micromatch';
+import { matchRestartRules } from '../src/build/restart.js';
+
+const FILE_COUNT = 5000;
+const ROUNDS = 5;
+
+const patterns = [
+  { match: '.*/entities/.*\\.java', reason: 'Entity class modification', severity: 'required' },
+  { match: '.*/META-INF/persistence\\.xml', reason: 'Persistence configuration', severity: 'required' },
+  { match: '.*EJB.*\\.java', reason: 'EJB implementation change', severity: 'recommended' },
+  { match: '.*/resources/.*\\.xml', reason: 'Resource configuration', severity: 'recommended' },
+  { match: '**/*.properties', reason: 'Properties change', severity: 'none' },
+  { match: '**/web/**/*.xhtml', reason: 'View change', severity: 'none' }
+];
+
+const files = Array.from({ length: FILE_COUNT }, (_, index) => {
+  const packageName = ['entities', 'services', 'web', 'resources', 'util'][index % 5];
+  const extension = ['java', 'xml', 'properties', 'xhtml'][index % 4];
+  return `core/src/main/java/com/acme/${packageName}/Module${index}${index % 7 === 0 ? 'EJB' : ''}.${extension}`;
+});
+
+// The pre-cache behaviour: both the glob and the regular expression are
+// compiled again for every file.
+function matchUncached(files, patterns) {
+  return files.flatMap((file) => patterns
+    .filter((rule) => {
+      let regex = null;
+      try {
+        regex = new RegExp(rule.match);
+      } catch {
+        regex = null;
+      }
+      return micromatch.isMatch(file, rule.match) || (regex !== null && regex.test(file));
+    })
+    .map((rule) => ({ file, ...rule })));
+}
+
+function measure(name, fn) {
+  fn();
+  const start = performance.now();
+  for (let round = 0; round < ROUNDS; round += 1) {
+    fn();
+  }
+  const perRound = (performance.now() - start) / ROUNDS;
+  console.log(`${name.padEnd(10)} ${perRound.toFixed(1).padStart(8)} ms per ${FILE_COUNT} files x ${patterns.length} rules`);
+  return perRound;
+}
+
+const uncached = measure('uncached', () => matchUncached(files, patterns));
+const cached = measure('cached', () => matchRestartRules(files, patterns));
+console.log(`speedup    ${(uncached / cached).toFixed(1)}x`);
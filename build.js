@@ -23,6 +23,8 @@ async function main() {
       banner: {
         js: "#!/usr/bin/env node\nimport { createRequire } from 'node:module';\nconst require = createRequire(import.meta.url);"
       },
+      // ssh2's optional native bindings fall back to pure JS when absent.
+      external: ['cpu-features', '*.node'],
       minify: true,
       legalComments: 'none'
     });
@@ -0,0 +1,100 @@
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+
+const DATE_PLACEHOLDERS = {
+  '%Y': (date) => String(date.getFullYear()),
+  '%m': (date) => String(date.getMonth() + 1).padStart(2, '0'),
+  '%d': (date) => String(date.getDate()).padStart(2, '0'),
+  '%%': () => '%'
+};
+
+function expandAuditLogPath(template, date = new Date()) {
+  return template.replace(/%[Ymd%]/g, (placeholder) => DATE_PLACEHOLDERS[placeholder](date));
+}
+
+function appendAuditEntry(template, entry, date = new Date()) {
+  if (!template) {
+    return null;
+  }
+
+  const logPath = expandAuditLogPath(template, date);
+  fs.mkdirSync(path.dirname(logPath), { recursive: true });
+  fs.appendFileSync(logPath, `${JSON.stringify({
+    time: date.toISOString(),
+    user: os.userInfo().username,
+    host: os.hostname(),
+    ...entry
+  })}\n`);
+
+  return logPath;
+}
+
+// Every file the template has produced so far: path segments holding
+// placeholders are matched against the directory listing.
+function findAuditLogFiles(template) {
+  const segments = path.resolve(template).split(path.sep);
+  let candidates = [segments[0] || path.sep];
+
+  for (const segment of segments.slice(1)) {
+    if (!/%[Ymd]/.test(segment)) {
+      candidates = candidates.map((candidate) => path.join(candidate, segment.replace(/%%/g, '%')));
+      continue;
+    }
+
+    const pattern = toSegmentPattern(segment);
+    candidates = candidates.flatMap((candidate) => {
+      try {
+        return fs.readdirSync(candidate).filter((name) => pattern.test(name)).map((name) => path.join(candidate, name));
+      } catch {
+        return [];
+      }
+    });
+  }
+
+  return candidates.filter((candidate) => fs.existsSync(candidate) && fs.statSync(candidate).isFile()).sort();
+}
+
+function toSegmentPattern(segment) {
+  const source = segment.split(/(%[Ymd%])/).map((part) => {
+    switch (part) {
+      case '%Y':
+        return '\\d{4}';
+      case '%m':
+      case '%d':
+        return '\\d{2}';
+      case '%%':
+        return '%';
+      default:
+        return part.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+    }
+  }).join('');
+
+  return new RegExp(`^${source}$`);
+}
+
+// Oldest first; lines that are not JSON (a truncated write) are skipped.
+function readAuditEntries(template) {
+  if (!template) {
+    return [];
+  }
+
+  return findAuditLogFiles(template)
+    .flatMap((logPath) => fs.readFileSync(logPath, 'utf8').split('\n'))
+    .filter(Boolean)
+    .flatMap((line) => {
+      try {
+        return [JSON.parse(line)];
+      } catch {
+        return [];
+      }
+    })
+    .sort((left, right) => String(left.time).localeCompare(String(right.time)));
+}
+
+export {
+  expandAuditLogPath,
+  appendAuditEntry,
+  findAuditLogFiles,
+  readAuditEntries
+};
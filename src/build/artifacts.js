@@ -1,5 +1,12 @@
+import fs from 'node:fs';
 import path from 'node:path';
+import ms from 'ms';
 import { globbySync } from 'globby';
+import { select } from '../utils.js';
+import { toIgnoreGlobs } from './restart.js';
+
+const DEFAULT_STALE_THRESHOLD = '2s';
+const DEFAULT_STALE_EXCLUDE = ['target/', '.git/', '.svn/', '.hg/', 'node_modules/', '.idea/'];
 
 function collectArtifacts(moduleInfo) {
   const targetPath = path.join(moduleInfo.path, 'target');
@@ -8,18 +15,95 @@ function collectArtifacts(moduleInfo) {
   return {
     targetPath,
     artifacts,
-    primaryArtifact: artifacts[0] ?? null
+    primaryArtifact: pickArtifact(moduleInfo, artifacts) ?? artifacts[0] ?? null
   };
 }
 
+// The pom's finalName decides; otherwise only an unambiguous single artifact.
+function pickArtifact(moduleInfo, artifacts) {
+  const expectedPath = getExpectedArtifactPath(moduleInfo);
+  const expected = artifacts.find((artifactPath) => path.resolve(artifactPath) === expectedPath);
+
+  if (expected) {
+    return expected;
+  }
+
+  return artifacts.length === 1 ? artifacts[0] : null;
+}
+
+// Asks only when target/ holds several candidates and none is the expected one.
+async function chooseModuleArtifact(moduleInfo) {
+  const { artifacts } = collectArtifacts(moduleInfo);
+  const picked = pickArtifact(moduleInfo, artifacts);
+
+  if (picked || artifacts.length === 0) {
+    return picked;
+  }
+
+  return select('jmw: several artifacts found in target/, which one should be deployed?', artifacts.map((artifactPath) => ({
+    title: path.basename(artifactPath),
+    value: artifactPath
+  })));
+}
+
+const EXTENSION_MAP = { ejb: 'jar', war: 'war', jar: 'jar', ear: 'ear', pom: 'pom' };
+
+function getArtifactExtension(packaging) {
+  return EXTENSION_MAP[packaging] || packaging;
+}
+
 function findArtifacts(targetPath, packaging) {
-  const extensionMap = { ejb: 'jar', war: 'war', jar: 'jar', ear: 'ear', pom: 'pom' };
-  const extension = extensionMap[packaging] || packaging;
+  return globbySync(`*.${getArtifactExtension(packaging)}`, { cwd: targetPath, absolute: true });
+}
+
+function getExpectedArtifactPath(moduleInfo) {
+  return path.join(moduleInfo.path, 'target', `${moduleInfo.finalName}.${getArtifactExtension(moduleInfo.packaging)}`);
+}
+
+// `stale_check` is false to disable the check, or { threshold, exclude } where
+// `exclude` uses the gitignore-style entries of restart_rules.ignore.
+function parseStaleCheck(value) {
+  if (value === false) {
+    return null;
+  }
+
+  const config = value ?? {};
+  const threshold = typeof config.threshold === 'number' ? config.threshold : ms(String(config.threshold ?? DEFAULT_STALE_THRESHOLD));
+  if (!Number.isFinite(threshold) || threshold < 0) {
+    throw new Error(`Invalid stale_check.threshold: ${config.threshold}`);
+  }
+
+  const exclude = config.exclude ?? DEFAULT_STALE_EXCLUDE;
+  if (!Array.isArray(exclude) || exclude.some((entry) => typeof entry !== 'string' || entry.length === 0)) {
+    throw new Error('stale_check.exclude must be a list of paths');
+  }
+
+  return { threshold, exclude };
+}
+
+// Module files modified more than `threshold` after the artifact, newest first.
+function findNewerSources(moduleInfo, artifactPath, staleCheck) {
+  const artifactTime = fs.statSync(artifactPath).mtimeMs;
 
-  return globbySync(`*.${extension}`, { cwd: targetPath, absolute: true });
+  return globbySync('**', {
+    cwd: moduleInfo.path,
+    ignore: staleCheck.exclude.flatMap(toIgnoreGlobs),
+    dot: true,
+    onlyFiles: true,
+    stats: true
+  })
+    .filter((entry) => entry.stats.mtimeMs - artifactTime > staleCheck.threshold)
+    .sort((a, b) => b.stats.mtimeMs - a.stats.mtimeMs)
+    .map((entry) => ({ file: entry.path, modified: entry.stats.mtime }));
 }
 
 export {
   collectArtifacts,
-  findArtifacts
+  pickArtifact,
+  chooseModuleArtifact,
+  findArtifacts,
+  getArtifactExtension,
+  getExpectedArtifactPath,
+  parseStaleCheck,
+  findNewerSources
 };
@@ -1,3 +1,4 @@
+import path from 'node:path';
 import { confirm } from '../utils.js';
 import { printWarning } from '../output.js';
 import { createBuildPlan, executeBuildPlan } from './maven.js';
@@ -40,7 +41,11 @@ async function buildModule(detection, profile, options = {}) {
     }
   );
 
-  const restartDecision = await evaluateRestartDecision(detection.module, detection.restartRules, options.restartOptions);
+  const restartDecision = await evaluateRestartDecision(detection.module, detection.restartRules, {
+    ...options.restartOptions,
+    artifactName: artifactReport.primaryArtifact ? path.basename(artifactReport.primaryArtifact) : null,
+    restartOverrides: detection.projectConfig.restart_overrides
+  });
   await lifecycle.emit(getRestartLifecycleStage(restartDecision.status), {
     detection,
     plan,
@@ -1,5 +1,6 @@
 import path from 'node:path';
 import { spawn } from 'node:child_process';
+import { formatCommand, printDebug } from '../output.js';
 
 function createBuildPlan(detection, profile, options = {}) {
   const { project, projectConfig, module: moduleInfo } = detection;
@@ -24,6 +25,8 @@ function getMavenExecutable() {
 }
 
 function runCommand(command, args, options = {}) {
+  printDebug(`exec ${formatCommand(command, args)}${options.cwd ? ` (cwd ${options.cwd})` : ''}`);
+
   return new Promise((resolve, reject) => {
     const child = spawn(command, args, {
       stdio: 'inherit',
@@ -1,19 +1,21 @@
 import {
   formatCommand,
   formatDetail,
+  glyph,
   joinDetails,
   printInfo,
   printSection,
   printSuccess,
   printWarning
 } from '../output.js';
+import { groupRestartMatches } from './restart.js';
 
 function showBuildPlan(plan) {
   printSection('build', [
     formatDetail('project', plan.project),
     formatDetail('module', plan.module.artifactId),
     formatDetail('packaging', plan.module.packaging)
-  ]);
+  ], { project: plan.project, module: plan.module.artifactId });
 
   const modulePath = plan.module.relativePath || 'root';
   printInfo(joinDetails([
@@ -53,8 +55,37 @@ function showRestartGuidance(decision) {
 
   printSection('restart', [statusLabels[decision.status] || statusLabels.unknown, decision.reason]);
 
-  decision.matches.forEach((match) => {
-    printInfo(`${match.severity} ${match.file} — ${match.reason}`);
+  showRestartMatches(decision.matches);
+}
+
+function showRestartMatches(matches) {
+  groupRestartMatches(matches).forEach((group) => {
+    const subject = group.files.length === 1 ? group.files[0] : `${group.files.length} files`;
+    printInfo(`${group.severity} ${subject} ${glyph('dash')} ${group.reason}`);
+  });
+}
+
+function showRestartTestResults(results) {
+  const matched = results.filter((result) => result.matches.length > 0).length;
+  printSection('restart-test', [
+    formatDetail('names', results.length),
+    formatDetail('matched', matched)
+  ]);
+
+  results.forEach((result) => {
+    if (result.matches.length === 0) {
+      printInfo(`${result.name} ${glyph('dash')} no rule matched`);
+      return;
+    }
+
+    result.matches.forEach((rule) => {
+      printInfo(joinDetails([
+        result.name,
+        formatDetail('severity', rule.severity),
+        formatDetail('rule', rule.match),
+        rule.reason
+      ]));
+    });
   });
 }
 
@@ -62,5 +93,7 @@ export {
   showBuildPlan,
   showBuildSuccess,
   showArtifactReport,
-  showRestartGuidance
+  showRestartGuidance,
+  showRestartMatches,
+  showRestartTestResults
 };
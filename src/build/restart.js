@@ -1,3 +1,5 @@
+import fs from 'node:fs';
+import path from 'node:path';
 import micromatch from 'micromatch';
 import simpleGit from 'simple-git';
 
@@ -9,42 +11,129 @@ const RESTART_STATUSES = Object.freeze({
 });
 
 async function evaluateRestartDecision(moduleInfo, restartRules, options = {}) {
+  const decision = decideRestartFromArtifact(moduleInfo, restartRules, options);
+  if (decision) {
+    return decision;
+  }
+
+  let modifiedFiles;
+  try {
+    modifiedFiles = await getModifiedFiles(moduleInfo, options);
+  } catch {
+    return createRestartDecision(RESTART_STATUSES.UNKNOWN, 'Unable to detect file changes');
+  }
+
+  return decideRestartFromFiles(moduleInfo, restartRules, modifiedFiles, readIgnoreFile(moduleInfo));
+}
+
+// Pure counterpart of evaluateRestartDecision: every input is passed in,
+// including the modified files (omit them when they could not be read) and
+// any extra ignore patterns normally read from the module's .jmwignore.
+function decideRestart(moduleInfo, restartRules, options = {}) {
+  const decision = decideRestartFromArtifact(moduleInfo, restartRules, options);
+  if (decision) {
+    return decision;
+  }
+
+  if (!options.modifiedFiles) {
+    return createRestartDecision(RESTART_STATUSES.UNKNOWN, 'Unable to detect file changes');
+  }
+
+  return decideRestartFromFiles(moduleInfo, restartRules, options.modifiedFiles, options.ignore);
+}
+
+function decideRestartFromArtifact(moduleInfo, restartRules, options = {}) {
   if (moduleInfo.isGlobalModule) {
     return createRestartDecision(RESTART_STATUSES.REQUIRED, 'Global module deployment');
   }
 
+  const override = matchRestartOverride(options.artifactName, options.restartOverrides);
+  if (override) {
+    return createRestartDecision(override.status, `Artifact matches restart override '${override.pattern}'`);
+  }
+
   if (moduleInfo.packaging === 'war') {
     return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'WAR hot-deployment');
   }
 
+  // EARs usually bundle EJB modules whose redeploy leaves stale remote
+  // references behind; use restart_overrides to opt single EARs out.
+  if (moduleInfo.packaging === 'ear' || /\.ear$/i.test(options.artifactName || '')) {
+    return createRestartDecision(RESTART_STATUSES.REQUIRED, 'EAR deployment');
+  }
+
   if (!restartRules || !restartRules.patterns) {
     return createRestartDecision(RESTART_STATUSES.UNKNOWN, 'No restart rules configured');
   }
 
-  try {
-    const modifiedFiles = await getModifiedFiles(moduleInfo, options);
-    if (modifiedFiles.length === 0) {
-      return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No files modified');
-    }
+  return null;
+}
 
-    const moduleFiles = filterFilesToModule(modifiedFiles, moduleInfo);
-    if (moduleFiles.length === 0) {
-      return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No files modified in target module');
-    }
+function decideRestartFromFiles(moduleInfo, restartRules, modifiedFiles, ignore = []) {
+  if (modifiedFiles.length === 0) {
+    return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No files modified');
+  }
+
+  const moduleFiles = filterIgnoredFiles(filterFilesToModule(modifiedFiles, moduleInfo), moduleInfo, [
+    ...(restartRules.ignore || []),
+    ...ignore
+  ]);
+  if (moduleFiles.length === 0) {
+    return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No files modified in target module');
+  }
+
+  const matches = matchRestartRules(moduleFiles, restartRules.patterns);
+  if (matches.length === 0) {
+    return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No critical files modified');
+  }
+
+  const status = matches.some((match) => match.severity === 'required')
+    ? RESTART_STATUSES.REQUIRED
+    : matches.some((match) => match.severity === 'recommended')
+      ? RESTART_STATUSES.RECOMMENDED
+      : RESTART_STATUSES.NOT_REQUIRED;
+
+  return createRestartDecision(status, 'Restart rules matched', { matches, modifiedFiles: moduleFiles });
+}
+
+function matchRestartOverride(artifactName, restartOverrides) {
+  if (!artifactName || !restartOverrides) {
+    return null;
+  }
 
-    const matches = matchRestartRules(moduleFiles, restartRules.patterns);
-    if (matches.length === 0) {
-      return createRestartDecision(RESTART_STATUSES.NOT_REQUIRED, 'No critical files modified');
+  for (const status of [RESTART_STATUSES.REQUIRED, RESTART_STATUSES.NOT_REQUIRED]) {
+    const pattern = (restartOverrides[status] || []).find((candidate) => micromatch.isMatch(artifactName, candidate));
+    if (pattern) {
+      return { status, pattern };
     }
+  }
 
-    const status = matches.some((match) => match.severity === 'required')
-      ? RESTART_STATUSES.REQUIRED
-      : RESTART_STATUSES.RECOMMENDED;
+  return null;
+}
 
-    return createRestartDecision(status, 'Restart rules matched', { matches, modifiedFiles: moduleFiles });
-  } catch {
-    return createRestartDecision(RESTART_STATUSES.UNKNOWN, 'Unable to detect file changes');
+const SEVERITY_ALIASES = Object.freeze({
+  none: RESTART_STATUSES.NOT_REQUIRED,
+  'not-required': RESTART_STATUSES.NOT_REQUIRED,
+  recommended: RESTART_STATUSES.RECOMMENDED,
+  required: RESTART_STATUSES.REQUIRED
+});
+
+function parseRestartSeverity(severity) {
+  const status = SEVERITY_ALIASES[severity];
+
+  if (!status) {
+    throw new Error(`Invalid restart severity '${severity}'. Expected one of: none, recommended, required`);
+  }
+
+  return status;
+}
+
+function overrideRestartDecision(decision, severity) {
+  if (!severity) {
+    return decision;
   }
+
+  return createRestartDecision(parseRestartSeverity(severity), `User-specified restart severity (analysis: ${decision.status})`);
 }
 
 function createRestartDecision(status, reason, extras = {}) {
@@ -81,42 +170,144 @@ function filterFilesToModule(modifiedFiles, moduleInfo) {
   });
 }
 
+const RULE_SEVERITY_ORDER = Object.freeze({ required: 1, recommended: 2, none: 3 });
+
+const IGNORE_FILE = '.jmwignore';
+
+function readIgnoreFile(moduleInfo) {
+  if (!moduleInfo.path) {
+    return [];
+  }
+
+  try {
+    return parseIgnorePatterns(fs.readFileSync(path.join(moduleInfo.path, IGNORE_FILE), 'utf8'));
+  } catch {
+    return [];
+  }
+}
+
+function parseIgnorePatterns(content) {
+  return content.split('\n')
+    .map((line) => line.trim())
+    .filter((line) => line && !line.startsWith('#'));
+}
+
+// Turns gitignore-style entries into globs over module-relative paths:
+// 'target/' prunes any target directory, '/docs' only the one at the root.
+function toIgnoreGlobs(pattern) {
+  const anchored = pattern.startsWith('/');
+  const body = pattern.replace(/^\/+/, '').replace(/\/+$/, '');
+  const glob = anchored || body.includes('/') ? body : `**/${body}`;
+
+  return pattern.endsWith('/') ? [`${glob}/**`] : [glob, `${glob}/**`];
+}
+
+function filterIgnoredFiles(files, moduleInfo, patterns = []) {
+  if (patterns.length === 0) {
+    return files;
+  }
+
+  const moduleRelativePath = moduleInfo.relativePath || '';
+  const matchers = patterns.flatMap(toIgnoreGlobs).map((glob) => micromatch.matcher(glob, { dot: true }));
+
+  return files.filter((file) => {
+    const relativeFile = moduleRelativePath ? file.slice(moduleRelativePath.length + 1) : file;
+    return !matchers.some((isIgnored) => isIgnored(relativeFile));
+  });
+}
+
+// Single pass over the modified files (read once from git, never walked on
+// disk). The rules are ordered most severe first, so the first rule a file
+// matches is the one it keeps and the remaining rules are skipped.
 function matchRestartRules(files, patterns) {
-  const matchesByFile = new Map();
-  const severityOrder = { required: 1, recommended: 2 };
+  const rules = [...patterns].sort((a, b) => (RULE_SEVERITY_ORDER[a.severity] ?? 3) - (RULE_SEVERITY_ORDER[b.severity] ?? 3));
+  const matches = [];
 
   for (const file of files) {
-    for (const rule of patterns) {
-      if (!matchesRule(file, rule.match)) {
-        continue;
-      }
-
-      const existing = matchesByFile.get(file);
-      if (!existing || severityOrder[rule.severity] < severityOrder[existing.severity]) {
-        matchesByFile.set(file, { file, ...rule });
-      }
+    const rule = rules.find((candidate) => matchesRule(file, candidate.match));
+    if (rule) {
+      matches.push({ file, ...rule });
     }
   }
 
-  return Array.from(matchesByFile.values());
+  return matches;
 }
-function matchesRule(file, pattern) {
-  if (micromatch.isMatch(file, pattern)) {
-    return true;
+
+function testRestartRules(names, patterns = []) {
+  return names.map((name) => ({
+    name,
+    matches: patterns.filter((rule) => matchesRule(name, rule.match))
+  }));
+}
+
+function groupRestartMatches(matches) {
+  const groups = new Map();
+
+  for (const match of matches) {
+    const key = `${match.severity}\u0000${match.reason}`;
+    const group = groups.get(key) || { severity: match.severity, reason: match.reason, files: [] };
+    group.files.push(match.file);
+    groups.set(key, group);
+  }
+
+  return Array.from(groups.values())
+    .sort((a, b) => (RULE_SEVERITY_ORDER[a.severity] ?? 3) - (RULE_SEVERITY_ORDER[b.severity] ?? 3));
+}
+
+const ruleMatchers = new Map();
+
+// Rules are tested against every modified file, so compile each pattern once.
+// A pattern is a glob, a regular expression, or both; it only throws when it
+// is neither, which is also what config loading checks.
+function getRuleMatcher(pattern) {
+  let matcher = ruleMatchers.get(pattern);
+
+  if (!matcher) {
+    if (typeof pattern !== 'string' || pattern === '') {
+      throw new Error('must be a non-empty glob or regular expression');
+    }
+
+    const isGlobMatch = compileOrNull(() => micromatch.matcher(pattern));
+    const regex = compileOrNull(() => new RegExp(pattern));
+    if (!isGlobMatch && !regex) {
+      // Report the regular expression error; it names the offending part.
+      new RegExp(pattern);
+    }
+
+    matcher = (file) => (isGlobMatch !== null && isGlobMatch(file)) || (regex !== null && regex.test(file));
+    ruleMatchers.set(pattern, matcher);
   }
 
+  return matcher;
+}
+
+function compileOrNull(compile) {
   try {
-    return new RegExp(pattern).test(file);
+    return compile();
   } catch {
-    return false;
+    return null;
   }
 }
 
+function matchesRule(file, pattern) {
+  return getRuleMatcher(pattern)(file);
+}
+
 export {
   RESTART_STATUSES,
   evaluateRestartDecision,
+  decideRestart,
   createRestartDecision,
+  matchRestartOverride,
+  parseRestartSeverity,
+  overrideRestartDecision,
   getModifiedFiles,
   filterFilesToModule,
-  matchRestartRules
+  filterIgnoredFiles,
+  toIgnoreGlobs,
+  parseIgnorePatterns,
+  matchRestartRules,
+  getRuleMatcher,
+  testRestartRules,
+  groupRestartMatches
 };
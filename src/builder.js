@@ -1,12 +1,20 @@
 export { buildModule } from './build/index.js';
 export { createBuildPlan, getMavenExecutable, runCommand, executeBuildPlan, buildMavenCommand, getProfiles } from './build/maven.js';
-export { collectArtifacts, findArtifacts } from './build/artifacts.js';
+export { collectArtifacts, pickArtifact, chooseModuleArtifact, findArtifacts, getArtifactExtension, getExpectedArtifactPath } from './build/artifacts.js';
 export {
   RESTART_STATUSES,
   evaluateRestartDecision,
+  decideRestart,
   createRestartDecision,
+  matchRestartOverride,
+  parseRestartSeverity,
+  overrideRestartDecision,
   getModifiedFiles,
   filterFilesToModule,
-  matchRestartRules
+  filterIgnoredFiles,
+  parseIgnorePatterns,
+  matchRestartRules,
+  testRestartRules,
+  groupRestartMatches
 } from './build/restart.js';
-export { showBuildPlan, showBuildSuccess, showArtifactReport, showRestartGuidance } from './build/reporting.js';
+export { showBuildPlan, showBuildSuccess, showArtifactReport, showRestartGuidance, showRestartMatches, showRestartTestResults } from './build/reporting.js';
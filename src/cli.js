@@ -2,17 +2,69 @@ import { Command } from 'commander';
 import { registerBuildCommand } from './commands/build.js';
 import { registerDeployCommand } from './commands/deploy.js';
 import { registerClientsCommand } from './commands/clients.js';
+import { registerStateCommands } from './commands/state.js';
+import { registerRemoteCommand } from './commands/remote.js';
+import { registerWhereCommand } from './commands/where.js';
+import { registerRestartTestCommand } from './commands/restart-test.js';
+import { registerGenerateCommand } from './commands/generate.js';
+import { registerRollbackCommand } from './commands/rollback.js';
+import { registerUndeployCommand } from './commands/undeploy.js';
+import { registerStatusCommand } from './commands/status.js';
+import { registerDoctorCommand } from './commands/doctor.js';
+import { registerConfigCommand } from './commands/config.js';
+import { registerLogsCommand } from './commands/logs.js';
+import { registerHistoryCommand } from './commands/history.js';
+import { registerCompletionCommand } from './commands/completion.js';
+import { LOG_FORMATS, OUTPUT_FORMATS, setColorEnabled, setLogFormat, setOutputFormat, setQuiet, setVerbose } from './output.js';
+import { handleCommandError, setJsonErrors } from './commands/shared.js';
+import { setAssumeYes } from './utils.js';
+import { setConfigPath } from './config.js';
 
 const program = new Command();
 
 program
   .name('jmw')
   .description('Java Maven WildFly - Interactive deployment helper')
-  .version('2.0.0');
+  .version('2.0.0')
+  .option('--config <path>', 'Use this jmw.config.json instead of searching upward (or JMW_CONFIG)')
+  .option('--log-format <format>', `Log output format (${LOG_FORMATS.join(', ')})`, 'text')
+  .option('-o, --output <format>', `Result output format (${OUTPUT_FORMATS.join(', ')}); json prints one object when deploy finishes`, 'text')
+  .option('--json-errors', 'Print failures as a single JSON object on stderr')
+  .option('-y, --yes', 'Answer yes to every confirmation prompt (required without a terminal)')
+  .option('--no-color', 'Disable colored output (also honors NO_COLOR)')
+  .option('-q, --quiet', 'Only print one-line outcomes, warnings and errors (no plan, details or tables)')
+  .option('-v, --verbose', 'Print debug lines (config files, paths, commands run, marker polling) on stderr')
+  .hook('preAction', (command) => {
+    try {
+      setJsonErrors(command.opts().jsonErrors);
+      setConfigPath(command.opts().config);
+      setLogFormat(command.opts().logFormat);
+      setOutputFormat(command.opts().output);
+      setColorEnabled(command.opts().color);
+      setAssumeYes(command.opts().yes);
+      setQuiet(command.opts().quiet);
+      setVerbose(command.opts().verbose);
+    } catch (error) {
+      handleCommandError(error);
+    }
+  });
 
 registerBuildCommand(program);
 registerDeployCommand(program);
 registerClientsCommand(program);
+registerStateCommands(program);
+registerRemoteCommand(program);
+registerWhereCommand(program);
+registerRestartTestCommand(program);
+registerGenerateCommand(program);
+registerRollbackCommand(program);
+registerUndeployCommand(program);
+registerStatusCommand(program);
+registerDoctorCommand(program);
+registerConfigCommand(program);
+registerLogsCommand(program);
+registerHistoryCommand(program);
+registerCompletionCommand(program);
 
 const helpText = `
 Examples:
@@ -21,7 +73,23 @@ Examples:
   $ jmw build TEST
   $ jmw build TEST --client metrocargo
   $ jmw deploy ./target/myapp.jar
+  $ jmw where ./target/myapp.jar
+  $ jmw status
+  $ jmw logs --grep 'ERROR|WFLYSRV'
+  $ jmw history --project mto -n 50
+  $ jmw doctor
+  $ jmw config show
+  $ jmw config validate
+  $ jmw config migrate --dry-run
   $ jmw clients
+  $ jmw restart-test --name src/main/java/entities/User.java
+  $ jmw remote test --client trieste
+  $ jmw --log-format json deploy ./target/myapp.jar
+  $ jmw -y -o json deploy ./target/myapp.jar
+  $ jmw --yes deploy ./target/*.war
+  $ jmw generate systemd --client trieste > wildfly.service
+  $ jmw state reset
+  $ source <(jmw completion bash)
 
 For more information: https://github.com/ppowo/jmw
 `;
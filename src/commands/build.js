@@ -1,33 +1,39 @@
 import path from 'node:path';
+import { chooseModuleArtifact } from '../build/artifacts.js';
 import { getJavaVersion, checkJavaVersion } from '../java.js';
 import {
   buildModule,
   createBuildTarget
 } from '../build/index.js';
 import {
+  deployArtifact,
   getWildflyConfig,
   createRemoteDeploymentPlan
 } from '../deploy/index.js';
+import { getAccessUrl } from '../deploy/wildfly.js';
 import { createLifecycle, LIFECYCLE_STAGES } from '../lifecycle/index.js';
 import {
   createBuildLifecycleHandlers,
+  createDeployLifecycleHandlers,
   createRemoteLifecycleHandlers
 } from '../lifecycle/console-handlers.js';
 import {
   formatDetail,
+  glyph,
   joinDetails,
-  printError,
   printInfo
 } from '../output.js';
-import { loadDetection, resolveClientSelection } from './shared.js';
+import { handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
 
 function registerBuildCommand(program) {
   program
     .command('build')
     .description('Build a Maven module')
     .argument('[profile]', 'Maven profile (e.g., TEST, PROD)')
+    .option('-p, --profile <name>', 'Maven profile, same as the positional argument (default: default_profile)')
     .option('-c, --client <name>', 'Target client (shows remote commands after build)')
     .option('--skip-tests', 'Skip tests during build')
+    .option('--deploy', 'Deploy the built artifact to the local WildFly after a successful build')
     .action(async (profile, options) => {
       try {
         const detection = loadDetection();
@@ -36,7 +42,9 @@ function registerBuildCommand(program) {
 
         const clientSelection = resolveClientSelection(detection.projectConfig, options.client);
 
-        assertClientRequired(detection.projectConfig, clientSelection);
+        if (!options.deploy) {
+          assertClientRequired(detection.projectConfig, clientSelection);
+        }
         printBuildContext(clientSelection);
 
         const lifecycle = createLifecycle([
@@ -44,7 +52,7 @@ function registerBuildCommand(program) {
           ...createRemoteLifecycleHandlers()
         ]);
 
-        const artifactPath = await buildModule(detection, profile, {
+        const artifactPath = await buildModule(detection, resolveProfile(profile, options.profile), {
           skipTests: options.skipTests,
           lifecycle
         });
@@ -61,19 +69,36 @@ function registerBuildCommand(program) {
           await lifecycle.emit(LIFECYCLE_STAGES.REMOTE_COMMAND_GENERATED, {
             detection,
             remotePlan,
+            accessUrl: getAccessUrl(artifactPath, detection.projectConfig, clientSelection.clientConfig.host),
             target: {
               ...createBuildTarget(detection),
               clientName: clientSelection.clientName
             }
           });
         }
+
+        if (options.deploy && artifactPath) {
+          const deployPath = await chooseModuleArtifact(detection.module);
+          if (deployPath) {
+            await deployArtifact(deployPath, detection, {
+              lifecycle: createLifecycle(createDeployLifecycleHandlers())
+            });
+          }
+        }
       } catch (error) {
-        printError(error.message);
-        process.exit(1);
+        handleCommandError(error);
       }
     });
 }
 
+function resolveProfile(argument, option) {
+  if (argument && option && argument !== option) {
+    throw new Error(`Conflicting profiles '${argument}' and --profile '${option}'`);
+  }
+
+  return option || argument;
+}
+
 function assertValidBuildLocation(detection, cwd = process.cwd()) {
   if (!detection.module.isReactorBuild || detection.module.relativePath !== '' || detection.module.packaging !== 'pom') {
     return;
@@ -113,7 +138,7 @@ async function assertJavaVersion(projectConfig, projectName) {
   const result = checkJavaVersion(requiredVersion, currentVersion);
   
   if (result.valid) {
-    printInfo(`Java ${currentVersion.full} ✓`);
+    printInfo(`Java ${currentVersion.full} ${glyph('check')}`);
     return;
   }
   
@@ -1,12 +1,11 @@
 import {
   formatDetail,
   joinDetails,
-  printError,
   printInfo,
   printSection,
   printWarning
 } from '../output.js';
-import { loadDetection } from './shared.js';
+import { handleCommandError, loadDetection } from './shared.js';
 
 function registerClientsCommand(program) {
   program
@@ -31,8 +30,7 @@ function registerClientsCommand(program) {
           ]));
         });
       } catch (error) {
-        printError(error.message);
-        process.exit(1);
+        handleCommandError(error);
       }
     });
 }
@@ -0,0 +1,29 @@
+import { COMPLETION_SHELLS, createCompletionSpec, listCompletionValues, renderCompletion } from '../completion.js';
+import { printPlain } from '../output.js';
+import { handleCommandError } from './shared.js';
+
+function registerCompletionCommand(program) {
+  program
+    .command('completion')
+    .description('Print a shell completion script')
+    .argument('<shell>', `Shell to complete for (${COMPLETION_SHELLS.join(', ')})`)
+    .action((shell) => {
+      try {
+        printPlain(renderCompletion(shell, createCompletionSpec(program)));
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+
+  // Called by the completion scripts for artifacts and project names.
+  program
+    .command('__complete', { hidden: true })
+    .argument('<kind>', 'artifacts or projects')
+    .action((kind) => {
+      listCompletionValues(kind).forEach((value) => printPlain(value));
+    });
+}
+
+export {
+  registerCompletionCommand
+};
@@ -0,0 +1,117 @@
+import fs from 'node:fs';
+import {
+  CONFIG_FILE_NAME,
+  CONFIG_VERSION,
+  describeConfigLayers,
+  loadConfig,
+  migrateConfig,
+  readConfigFile,
+  redactConfig,
+  resolveConfigPath,
+  resolveUserConfigPath
+} from '../config.js';
+import { runConfigValidation } from '../doctor.js';
+import { showConfigValidation } from '../deploy/reporting.js';
+import { JmwError } from '../errors.js';
+import {
+  formatDetail,
+  printInfo,
+  printPlain,
+  printSection,
+  printSuccess,
+  printWarning
+} from '../output.js';
+import { EXIT_CODES, handleCommandError } from './shared.js';
+
+function registerConfigCommand(program) {
+  const configCommand = program
+    .command('config')
+    .description('Maintain jmw config files');
+
+  configCommand
+    .command('migrate')
+    .description(`Upgrade a config file in place to schema version ${CONFIG_VERSION}`)
+    .option('--user', 'Migrate the per-user config instead of the project config')
+    .option('--dry-run', 'Show the changes without writing the file')
+    .action(migrate);
+
+  configCommand
+    .command('show')
+    .description('Print the effective config: merged, env-expanded, passwords redacted')
+    .action(show);
+
+  configCommand
+    .command('validate')
+    .description('Check the merged config of every project without deploying')
+    .action(validate);
+}
+
+function show() {
+  try {
+    const config = loadConfig();
+
+    printSection('config show', [formatDetail('precedence', describeConfigLayers(resolveUserConfigPath(), resolveConfigPath()))]);
+    printPlain(JSON.stringify(redactConfig(config), null, 2));
+  } catch (error) {
+    handleCommandError(error);
+  }
+}
+
+function validate() {
+  try {
+    const result = runConfigValidation();
+    showConfigValidation(result);
+
+    if (result.projects.some((project) => project.problems.length > 0)) {
+      process.exitCode = EXIT_CODES.FAILURE;
+    }
+  } catch (error) {
+    handleCommandError(error);
+  }
+}
+
+function migrate(options) {
+  try {
+    const filePath = resolveMigrationTarget(options.user);
+    const { config, fromVersion, changes } = migrateConfig(readConfigFile(filePath), filePath);
+
+    printSection('config migrate', [
+      formatDetail('file', filePath),
+      formatDetail('version', fromVersion === CONFIG_VERSION ? CONFIG_VERSION : `${fromVersion} -> ${CONFIG_VERSION}`)
+    ]);
+
+    if (fromVersion === CONFIG_VERSION) {
+      printSuccess(`already at config version ${CONFIG_VERSION}`);
+      return;
+    }
+
+    changes.forEach((change) => printInfo(formatDetail('change', change)));
+
+    if (options.dryRun) {
+      printWarning('dry run: the config file was not written');
+      return;
+    }
+
+    fs.writeFileSync(filePath, `${JSON.stringify(config, null, 2)}\n`);
+    printSuccess(`migrated ${filePath} to config version ${CONFIG_VERSION}`);
+  } catch (error) {
+    handleCommandError(error);
+  }
+}
+
+function resolveMigrationTarget(user) {
+  const filePath = user ? resolveUserConfigPath() : resolveConfigPath();
+
+  if (!filePath) {
+    const message = user
+      ? 'No per-user config in $XDG_CONFIG_HOME/jmw or ~/.config/jmw'
+      : `No ${CONFIG_FILE_NAME} found in this directory or its parents; pass --config or set JMW_CONFIG`;
+    throw new JmwError(message, { code: 'CONFIG_NOT_FOUND', phase: 'config' });
+  }
+
+  return filePath;
+}
+
+export {
+  registerConfigCommand
+};
@@ -1,37 +1,364 @@
 import fs from 'node:fs';
 import path from 'node:path';
-import { deployArtifact } from '../deploy/index.js';
+import { globbySync } from 'globby';
+import { deployArtifact, deployArtifactRemote, preflightParallelDeploy } from '../deploy/index.js';
+import { chooseModuleArtifact, getArtifactExtension, getExpectedArtifactPath } from '../build/artifacts.js';
+import { showDeployBatch, showDeployResultJson, showMultiDeploySummary } from '../deploy/reporting.js';
+import { getLocalTargetPaths, getWildflyConfig } from '../deploy/wildfly.js';
+import { getRemoteTargetPaths } from '../deploy/remote.js';
+import { confirm, mapConcurrent } from '../utils.js';
+import { JmwError } from '../errors.js';
 import { createLifecycle } from '../lifecycle/index.js';
-import { createDeployLifecycleHandlers } from '../lifecycle/console-handlers.js';
+import {
+  createDeployLifecycleHandlers,
+  createRemoteLifecycleHandlers
+} from '../lifecycle/console-handlers.js';
 import {
   formatDetail,
+  getOutputFormat,
   printError,
   printInfo,
-  printSection
+  printSection,
+  printWarning,
+  withBufferedOutput
 } from '../output.js';
-import { loadDetection } from './shared.js';
+import { EXIT_CODES, handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
 
 function registerDeployCommand(program) {
   program
     .command('deploy')
     .description('Deploy artifact to WildFly')
-    .argument('<artifact>', 'Path to artifact JAR/WAR file')
-    .action(async (artifact) => {
+    .argument('[artifacts...]', 'Paths or glob patterns of artifact JAR/WAR files (default: the module artifact in target/)')
+    .option('--only <names>', 'Deploy only the matched artifacts with these comma-separated names')
+    .option('--artifact-from-pom', 'Deploy target/<finalName>.<packaging> as declared in the module pom.xml')
+    .option('--restart', 'Restart WildFly after deployment when a restart is required')
+    .option('--force-restart', 'Restart WildFly after deployment unless the analysis finds no restart is needed')
+    .option('--no-restart', 'Never restart and skip the restart advice after deployment')
+    .option('--restart-severity <severity>', 'Override the restart analysis (none, recommended, required)')
+    .option('--also-global', 'Also copy the artifact to its global module path after the normal deployment')
+    .option('--redeploy', 'Standalone mode: undeploy the current deployment before deploying the new one')
+    .option('--timeout <duration>', 'How long to wait for .deployed or .failed (standalone, default deploy_timeout, 60s) or for each jboss-cli call (domain, default cli_timeout, 120s)')
+    .option('--retry-deploy <count>', 'Standalone mode: retry the copy and marker up to <count> times when WildFly writes .failed')
+    .option('--retry-delay <duration>', 'Delay between --retry-deploy attempts', '5s')
+    .option('--pre-undeploy-verify', 'Domain mode: show current server group deployments before undeploying')
+    .option('--step', 'Domain mode: confirm before undeploying the existing deployment')
+    .option('--all-relevant-server-groups', 'Domain mode: redeploy to every server group that has the artifact, falling back to server_groups')
+    .option('--controller <host[:port]>', 'jboss-cli controller to connect to (default controller_host:controller_port, localhost:9990)')
+    .option('--parallel <count>', 'Standalone mode: deploy up to <count> artifacts at once (default 1)')
+    .option('--watch', 'Follow server.log after the deploy until it reports success or failure for the artifact')
+    .option('--watch-timeout <duration>', 'How long --watch follows server.log (default watch_timeout, 30s)')
+    .option('--fail-on-unhealthy', 'Exit non-zero when the health_check URL never returns the expected status')
+    .option('--no-notify', 'Do not send the configured deploy notification')
+    .option('--note <text>', 'Free-text comment recorded in the audit log')
+    .option('--force', 'Deploy even if the artifact name violates artifact_name_pattern')
+    .option('-c, --client <name>', 'Target client for --remote')
+    .option('--remote', 'Deploy to the client host over SSH instead of the local WildFly')
+    .option('--dry-run', 'Show what would be copied or run without changing anything (with --remote, check SSH access and remote paths)')
+    .action(async (artifacts, options) => {
       try {
         const detection = loadDetection();
-        const artifactPath = validateArtifactPath(artifact);
+        const resolution = filterArtifactPaths(options.artifactFromPom
+          ? resolvePomArtifact(detection.module)
+          : artifacts.length === 0
+            ? await resolveTargetArtifact(detection.module)
+            : resolveArtifactPaths(artifacts), options.only);
+        const clientSelection = resolveRemoteClient(detection.projectConfig, options);
         const lifecycle = createLifecycle([
-          ...createDeployLifecycleHandlers()
+          ...createDeployLifecycleHandlers(),
+          ...createRemoteLifecycleHandlers()
         ]);
 
-        await deployArtifact(artifactPath, detection, { lifecycle });
+        resolution.unmatched.forEach((pattern) => {
+          printWarning(`no artifact matches '${pattern}'`);
+        });
+
+        if (artifacts.length > 0 && resolution.artifactPaths.length === 1) {
+          warnUnexpectedArtifact(resolution.artifactPaths[0], detection.module);
+        }
+
+        const batch = resolution.artifactPaths.length > 1;
+        const parallel = resolveParallelism(options.parallel, batch, detection, clientSelection);
+        if (batch && !options.dryRun) {
+          showDeployBatch(resolution.artifactPaths.map((artifactPath) => ({
+            artifactPath,
+            targets: getBatchTargets(artifactPath, detection, clientSelection)
+          })), clientSelection?.clientName);
+
+          if (!await confirm(`jmw: deploy ${resolution.artifactPaths.length} artifacts?`)) {
+            printWarning('deployment cancelled');
+            return;
+          }
+        }
+
+        // Buffered workers cannot prompt, so their questions are asked first.
+        const preflight = parallel > 1
+          ? await preflightParallelDeploy(resolution.artifactPaths, detection, { dryRun: options.dryRun })
+          : null;
+        if (parallel > 1 && !preflight) {
+          return;
+        }
+
+        const deployOne = (artifactPath) => {
+          if (clientSelection) {
+            return deployArtifactRemote(artifactPath, detection, clientSelection, {
+              lifecycle,
+              confirmed: batch,
+              restart: options.restart,
+              forceRestart: options.forceRestart,
+              restartSeverity: options.restartSeverity,
+              force: options.force,
+              note: options.note,
+              notify: options.notify,
+              dryRun: options.dryRun
+            });
+          }
+
+          return deployArtifact(artifactPath, detection, {
+            lifecycle,
+            restart: options.restart,
+            forceRestart: options.forceRestart,
+            restartSeverity: options.restartSeverity,
+            preUndeployVerify: options.preUndeployVerify,
+            step: options.step,
+            controller: options.controller,
+            allRelevantServerGroups: options.allRelevantServerGroups,
+            redeploy: options.redeploy,
+            alsoGlobal: options.alsoGlobal,
+            timeout: options.timeout,
+            retryDeploy: options.retryDeploy,
+            retryDelay: options.retryDelay,
+            watch: options.watch,
+            watchTimeout: options.watchTimeout,
+            failOnUnhealthy: options.failOnUnhealthy,
+            concurrentArtifacts: parallel > 1 ? resolution.artifactPaths.map((artifactPath) => path.basename(artifactPath)) : [],
+            preflighted: parallel > 1,
+            staleUndeploys: preflight?.staleUndeploys,
+            dryRun: options.dryRun,
+            confirmed: batch,
+            force: options.force,
+            note: options.note,
+            notify: options.notify
+          });
+        };
+
+        const deployEntry = async (artifactPath) => {
+          if (preflight && !preflight.artifactPaths.includes(artifactPath)) {
+            return { artifactPath, result: null };
+          }
+
+          try {
+            return { artifactPath, result: await deployOne(artifactPath) };
+          } catch (error) {
+            if (!batch) {
+              if (getOutputFormat() === 'json') {
+                showDeployResultJson([{ artifactPath, error }]);
+              }
+              throw error;
+            }
+
+            printError(`${path.basename(artifactPath)}: ${error.message}`, { code: error.code, phase: error.phase, artifact: path.basename(artifactPath) });
+            return { artifactPath, error };
+          }
+        };
+
+        const entries = await mapConcurrent(resolution.artifactPaths, parallel, parallel > 1
+          ? (artifactPath) => withBufferedOutput(() => deployEntry(artifactPath))
+          : deployEntry);
+
+        if (getOutputFormat() === 'json') {
+          showDeployResultJson(entries);
+        } else if (batch && !options.dryRun) {
+          showMultiDeploySummary(entries);
+        }
+
+        const exitCode = getDeployExitCode(entries, resolution.unmatched);
+        if (exitCode !== undefined) {
+          process.exitCode = exitCode;
+        }
       } catch (error) {
-        printError(error.message);
-        process.exit(1);
+        handleCommandError(error);
       }
     });
 }
 
+// Every artifact failing is a failure; some failing, or a pattern matching
+// nothing, is a partial success.
+function getDeployExitCode(entries, unmatched = []) {
+  const failed = entries.filter((entry) => entry.error).length;
+
+  if (failed === entries.length) {
+    return EXIT_CODES.FAILURE;
+  }
+
+  if (failed > 0 || unmatched.length > 0) {
+    return EXIT_CODES.PARTIAL;
+  }
+
+  return undefined;
+}
+
+// Standalone deploys only copy files and write markers, so they can overlap;
+// jboss-cli deploys (domain mode) and remote steps stay one at a time.
+function resolveParallelism(value, batch, detection, clientSelection) {
+  if (value === undefined) {
+    return 1;
+  }
+
+  const count = Number(value);
+  if (!Number.isInteger(count) || count < 1) {
+    throw new Error(`Invalid --parallel count '${value}'. Expected a positive integer`);
+  }
+
+  if (count === 1 || !batch) {
+    return 1;
+  }
+
+  if (clientSelection || getWildflyConfig(detection.projectConfig).mode !== 'standalone') {
+    printInfo(formatDetail('parallel', `ignored: ${clientSelection ? 'remote' : 'domain mode'} deploys run one artifact at a time`));
+    return 1;
+  }
+
+  return count;
+}
+
+function warnUnexpectedArtifact(artifactPath, moduleInfo) {
+  const artifactName = path.basename(artifactPath);
+
+  if (moduleInfo.packaging !== 'pom' && artifactName !== moduleInfo.artifactName) {
+    printWarning(`${artifactName} is not the artifact pom.xml produces for ${moduleInfo.artifactId} (${moduleInfo.artifactName})`);
+  }
+}
+
+function getBatchTargets(artifactPath, detection, clientSelection) {
+  const wildflyConfig = getWildflyConfig(detection.projectConfig);
+
+  if (clientSelection) {
+    return getRemoteTargetPaths(artifactPath, wildflyConfig, clientSelection.clientConfig, detection.module);
+  }
+
+  const targetPaths = getLocalTargetPaths(artifactPath, wildflyConfig, detection.module);
+  return targetPaths.length > 0 ? targetPaths : [`server ${wildflyConfig.serverGroups.length > 1 ? 'groups' : 'group'} ${wildflyConfig.serverGroups.join(', ')}`];
+}
+
+function resolveRemoteClient(projectConfig, options) {
+  if (!options.remote) {
+    return null;
+  }
+
+  if (options.watch) {
+    throw new Error('--watch follows the local server.log; for --remote, use the tail command from the remote guide');
+  }
+
+  const clientSelection = resolveClientSelection(projectConfig, options.client);
+  if (!clientSelection.clientConfig) {
+    const availableClients = projectConfig.clients ? Object.keys(projectConfig.clients) : [];
+    throw new Error(`--remote requires --client. Available clients: ${availableClients.join(', ') || 'none'}`);
+  }
+
+  return clientSelection;
+}
+
+function resolvePomArtifact(moduleInfo) {
+  const artifactPath = getExpectedArtifactPath(moduleInfo);
+
+  if (!fs.existsSync(artifactPath)) {
+    throw new JmwError(`Artifact declared by pom.xml not found: ${artifactPath}. Run 'jmw build' first.`, {
+      code: 'ARTIFACT_NOT_FOUND',
+      phase: 'resolve',
+      artifact: artifactPath
+    });
+  }
+
+  return {
+    artifactPaths: [artifactPath],
+    unmatched: []
+  };
+}
+
+async function resolveTargetArtifact(moduleInfo) {
+  const artifactPath = await chooseModuleArtifact(moduleInfo);
+
+  if (!artifactPath) {
+    throw new JmwError(`No .${getArtifactExtension(moduleInfo.packaging)} artifact found in ${path.join(moduleInfo.path, 'target')}. Run 'jmw build' first or pass an artifact path.`, {
+      code: 'ARTIFACT_NOT_FOUND',
+      phase: 'resolve'
+    });
+  }
+
+  return {
+    artifactPaths: [artifactPath],
+    unmatched: []
+  };
+}
+
+function resolveArtifactPaths(patterns, cwd = process.cwd()) {
+  if (patterns.length === 0) {
+    throw new Error('No artifact given. Pass an artifact path or use --artifact-from-pom.');
+  }
+
+  const artifactPaths = new Set();
+  const unmatched = [];
+
+  for (const pattern of patterns) {
+    // An existing file is taken literally, so names with glob characters
+    // such as [ ( { ! still deploy.
+    const literalPath = path.resolve(cwd, pattern);
+    const matches = fs.existsSync(literalPath) && fs.statSync(literalPath).isFile()
+      ? [literalPath]
+      : globbySync(pattern, { cwd, absolute: true, onlyFiles: true });
+
+    if (matches.length === 0) {
+      unmatched.push(pattern);
+      continue;
+    }
+
+    matches.forEach((match) => artifactPaths.add(path.resolve(match)));
+  }
+
+  if (artifactPaths.size === 0) {
+    throw new JmwError(`No artifacts match: ${patterns.join(', ')}`, {
+      code: 'ARTIFACT_NOT_FOUND',
+      phase: 'resolve'
+    });
+  }
+
+  return {
+    artifactPaths: Array.from(artifactPaths).sort(),
+    unmatched
+  };
+}
+
+function filterArtifactPaths(resolution, only) {
+  if (!only) {
+    return resolution;
+  }
+
+  const names = only.split(',').map((name) => stripExtension(name.trim())).filter(Boolean);
+  const candidates = new Set(resolution.artifactPaths.map(getArtifactName));
+  const unknown = names.filter((name) => !candidates.has(name));
+
+  if (unknown.length > 0) {
+    throw new JmwError(`--only names not among the matched artifacts: ${unknown.join(', ')}. Valid names: ${Array.from(candidates).join(', ')}`, {
+      code: 'ARTIFACT_NOT_FOUND',
+      phase: 'resolve'
+    });
+  }
+
+  const selected = new Set(names);
+  return {
+    ...resolution,
+    artifactPaths: resolution.artifactPaths.filter((artifactPath) => selected.has(getArtifactName(artifactPath)))
+  };
+}
+
+function getArtifactName(artifactPath) {
+  return stripExtension(path.basename(artifactPath));
+}
+
+function stripExtension(name) {
+  return name.replace(/\.(jar|war|ear)$/i, '');
+}
+
 function validateArtifactPath(artifact) {
   const artifactPath = path.resolve(artifact);
 
@@ -46,12 +373,17 @@ function printDeployContext(detection, artifact) {
   printSection('deploy', [
     formatDetail('project', detection.project),
     formatDetail('module', detection.module.artifactId)
-  ]);
-  printInfo(formatDetail('artifact', artifact));
+  ], { project: detection.project, module: detection.module.artifactId });
+  printInfo(formatDetail('artifact', artifact), { phase: 'deploy', artifact: path.basename(artifact) });
 }
 
 export {
   registerDeployCommand,
+  resolveArtifactPaths,
+  getDeployExitCode,
+  resolvePomArtifact,
+  resolveTargetArtifact,
+  filterArtifactPaths,
   validateArtifactPath,
   printDeployContext
 };
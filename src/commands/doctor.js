@@ -0,0 +1,25 @@
+import { runDoctorChecks } from '../doctor.js';
+import { showDoctorResults } from '../deploy/reporting.js';
+import { EXIT_CODES, handleCommandError } from './shared.js';
+
+function registerDoctorCommand(program) {
+  program
+    .command('doctor')
+    .description('Check the configuration and local WildFly setup without deploying')
+    .action(() => {
+      try {
+        const checks = runDoctorChecks();
+        showDoctorResults(checks);
+
+        if (checks.some((check) => !check.passed && !check.soft)) {
+          process.exitCode = EXIT_CODES.FAILURE;
+        }
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerDoctorCommand
+};
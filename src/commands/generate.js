@@ -0,0 +1,39 @@
+import { createSystemdUnit } from '../deploy/systemd.js';
+import { getWildflyConfig } from '../deploy/wildfly.js';
+import { printPlain } from '../output.js';
+import { handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
+
+function registerGenerateCommand(program) {
+  const generate = program
+    .command('generate')
+    .description('Generate integration files for the current project');
+
+  generate
+    .command('systemd')
+    .description('Print a suggested systemd unit for the project WildFly')
+    .option('-c, --client <name>', 'Use the client wildfly_path instead of the local wildfly_root')
+    .option('--service-name <name>', 'systemd service name', 'wildfly')
+    .option('--user <name>', 'User the service runs as', 'wildfly')
+    .action((options) => {
+      try {
+        const detection = loadDetection();
+        const wildflyConfig = getWildflyConfig(detection.projectConfig);
+        const clientConfig = options.client
+          ? resolveClientSelection(detection.projectConfig, options.client).clientConfig
+          : null;
+
+        printPlain(createSystemdUnit(clientConfig?.wildfly_path || wildflyConfig.root, wildflyConfig.mode, {
+          project: detection.project,
+          serviceName: options.serviceName,
+          user: options.user,
+          sshUser: clientConfig?.user
+        }));
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerGenerateCommand
+};
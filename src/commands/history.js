@@ -0,0 +1,51 @@
+import { loadConfig } from '../config.js';
+import { readAuditEntries } from '../audit.js';
+import { showDeployHistory } from '../deploy/reporting.js';
+import { getOutputFormat, printPlain } from '../output.js';
+import { handleCommandError } from './shared.js';
+
+const DEFAULT_HISTORY_LIMIT = 20;
+
+function registerHistoryCommand(program) {
+  program
+    .command('history')
+    .description('Show recent deploys recorded in the audit log')
+    .option('-p, --project <name>', 'Only show entries of this project')
+    .option('-n, --limit <count>', `Number of entries to show, newest last (default ${DEFAULT_HISTORY_LIMIT})`)
+    .option('--json', 'Print the entries as JSON lines (also with --output json)')
+    .action((options) => {
+      try {
+        const limit = parseLimit(options.limit);
+        const config = loadConfig();
+        const entries = readAuditEntries(config.audit_log)
+          .filter((entry) => !options.project || entry.project === options.project)
+          .slice(-limit);
+
+        if (options.json || getOutputFormat() === 'json') {
+          entries.forEach((entry) => printPlain(JSON.stringify(entry)));
+          return;
+        }
+
+        showDeployHistory(config.audit_log, entries);
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+function parseLimit(value) {
+  if (value === undefined) {
+    return DEFAULT_HISTORY_LIMIT;
+  }
+
+  const count = Number(value);
+  if (!Number.isInteger(count) || count < 1) {
+    throw new Error(`Invalid --limit count '${value}'. Expected a positive integer`);
+  }
+
+  return count;
+}
+
+export {
+  registerHistoryCommand
+};
@@ -0,0 +1,67 @@
+import { getLocalServerLogPaths, getWildflyConfig } from '../deploy/wildfly.js';
+import { followRemoteLog, followServerLogs } from '../deploy/log-watch.js';
+import { printPlain } from '../output.js';
+import { handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
+
+const DEFAULT_LOG_LINES = 20;
+
+function registerLogsCommand(program) {
+  program
+    .command('logs')
+    .description('Follow the WildFly server log of the current project')
+    .option('-n, --lines <count>', `Lines to print before following (default ${DEFAULT_LOG_LINES})`)
+    .option('--grep <pattern>', 'Only print lines matching this regular expression')
+    .option('--remote', 'Follow the server log of --client over SSH')
+    .option('-c, --client <name>', 'Target client for --remote')
+    .action(async (options) => {
+      try {
+        const lines = parseLineCount(options.lines);
+        const pattern = parseGrepPattern(options.grep);
+        const detection = loadDetection();
+        const wildflyConfig = getWildflyConfig(detection.projectConfig);
+
+        if (options.remote) {
+          const clientSelection = resolveClientSelection(detection.projectConfig, options.client);
+          if (!clientSelection.clientConfig) {
+            throw new Error('--remote requires --client');
+          }
+
+          await followRemoteLog(wildflyConfig, clientSelection.clientConfig, { lines, pattern, onLine: printPlain });
+          return;
+        }
+
+        await followServerLogs(getLocalServerLogPaths(wildflyConfig), { lines, pattern, onLine: printPlain });
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+function parseLineCount(value) {
+  if (value === undefined) {
+    return DEFAULT_LOG_LINES;
+  }
+
+  const count = Number(value);
+  if (!Number.isInteger(count) || count < 0) {
+    throw new Error(`Invalid --lines count '${value}'. Expected a non-negative integer`);
+  }
+
+  return count;
+}
+
+function parseGrepPattern(value) {
+  if (value === undefined) {
+    return null;
+  }
+
+  try {
+    return new RegExp(value);
+  } catch (error) {
+    throw new Error(`Invalid --grep pattern '${value}': ${error.message}`);
+  }
+}
+
+export {
+  registerLogsCommand
+};
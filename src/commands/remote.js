@@ -0,0 +1,45 @@
+import { getWildflyConfig } from '../deploy/wildfly.js';
+import { createRemoteChecks, runRemoteChecks, withRemoteRunner } from '../deploy/remote.js';
+import { showRemoteCheckResults } from '../deploy/reporting.js';
+import {
+  formatDetail,
+  printSection
+} from '../output.js';
+import { EXIT_CODES, handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
+
+function registerRemoteCommand(program) {
+  const remote = program
+    .command('remote')
+    .description('Inspect remote client hosts');
+
+  remote
+    .command('test')
+    .description('Check SSH access and remote paths for a client')
+    .requiredOption('-c, --client <name>', 'Target client')
+    .action(async (options) => {
+      try {
+        const detection = loadDetection();
+        const clientSelection = resolveClientSelection(detection.projectConfig, options.client);
+        const wildflyConfig = getWildflyConfig(detection.projectConfig);
+
+        printSection('remote test', [
+          formatDetail('client', clientSelection.clientName),
+          formatDetail('host', clientSelection.clientConfig.host)
+        ]);
+
+        const checks = createRemoteChecks(wildflyConfig, clientSelection.clientConfig, detection.module);
+        const results = await withRemoteRunner(clientSelection.clientConfig, null, (run) => runRemoteChecks(checks, run));
+        showRemoteCheckResults(results);
+
+        if (results.some((result) => !result.passed)) {
+          process.exitCode = EXIT_CODES.FAILURE;
+        }
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerRemoteCommand
+};
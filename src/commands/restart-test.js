@@ -0,0 +1,41 @@
+import fs from 'node:fs';
+import { loadConfig } from '../config.js';
+import { testRestartRules } from '../build/restart.js';
+import { showRestartTestResults } from '../build/reporting.js';
+import { handleCommandError } from './shared.js';
+
+function collectName(value, names) {
+  return [...names, value];
+}
+
+function readNamesFile(filePath) {
+  return fs.readFileSync(filePath, 'utf8')
+    .split('\n')
+    .map((line) => line.trim())
+    .filter((line) => line && !line.startsWith('#'));
+}
+
+function registerRestartTestCommand(program) {
+  program
+    .command('restart-test')
+    .description('Check file names against the configured restart rules')
+    .option('--name <path>', 'File name or path to test (repeatable)', collectName, [])
+    .option('--file <path>', 'Read names to test from a file, one per line')
+    .action((options) => {
+      try {
+        const names = [...options.name, ...(options.file ? readNamesFile(options.file) : [])];
+        if (names.length === 0) {
+          throw new Error('Provide at least one --name or a --file with names to test');
+        }
+
+        const restartRules = loadConfig().restart_rules;
+        showRestartTestResults(testRestartRules(names, restartRules?.patterns));
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerRestartTestCommand
+};
@@ -0,0 +1,21 @@
+import path from 'node:path';
+import { rollbackArtifact } from '../deploy/index.js';
+import { handleCommandError, loadDetection } from './shared.js';
+
+function registerRollbackCommand(program) {
+  program
+    .command('rollback')
+    .description('Restore the most recent backup of a deployed artifact')
+    .argument('<artifact>', 'Artifact path or file name')
+    .action(async (artifact) => {
+      try {
+        await rollbackArtifact(path.basename(artifact), loadDetection());
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerRollbackCommand
+};
@@ -1,9 +1,38 @@
-import { loadConfig, getClientConfig } from '../config.js';
-import { detectProject } from '../project/detector.js';
+import path from 'node:path';
+import { createConfigNotFoundError, loadConfig, getClientConfig, resolveConfigPath, resolveUserConfigPath } from '../config.js';
+import { detectProject, findProjectConfig } from '../project/detector.js';
+import { EXIT_CODES, toErrorReport } from '../errors.js';
+import { printDebug, printError, printErrorReport } from '../output.js';
 
-function loadDetection(cwd) {
-  const config = loadConfig();
-  return detectProject(config, cwd);
+let jsonErrors = false;
+
+function setJsonErrors(enabled) {
+  jsonErrors = Boolean(enabled);
+}
+
+function handleCommandError(error) {
+  if (jsonErrors) {
+    printErrorReport(toErrorReport(error));
+  } else {
+    printError(error.message, { code: error.code, phase: error.phase, artifact: error.artifact });
+  }
+
+  process.exit(error.exitCode ?? EXIT_CODES.FAILURE);
+}
+
+function loadDetection(cwd = process.cwd()) {
+  const config = loadConfig(cwd);
+
+  // Without any config file only the built-in projects exist; say where a
+  // file was looked for instead of just "not within any configured project".
+  if (!findProjectConfig(config, path.resolve(cwd)) && !resolveConfigPath(cwd) && !resolveUserConfigPath()) {
+    throw createConfigNotFoundError(cwd);
+  }
+
+  const detection = detectProject(config, cwd);
+  printDebug(`project ${detection.project} · module ${detection.module.artifactId} at ${detection.module.path}`);
+
+  return detection;
 }
 
 function resolveClientSelection(projectConfig, requestedClient) {
@@ -23,6 +52,9 @@ function resolveClientSelection(projectConfig, requestedClient) {
 }
 
 export {
+  EXIT_CODES,
+  setJsonErrors,
+  handleCommandError,
   loadDetection,
   resolveClientSelection
 };
@@ -0,0 +1,70 @@
+import { config, expandPaths, loadConfig } from '../config.js';
+import { clearState, getStateDir, listStateEntries } from '../state.js';
+import { confirm } from '../utils.js';
+import { handleCommandError } from './shared.js';
+import {
+  formatDetail,
+  printInfo,
+  printSection,
+  printSuccess,
+  printWarning
+} from '../output.js';
+
+function registerStateCommands(program) {
+  program
+    .command('state')
+    .description('Manage jmw local state')
+    .command('reset')
+    .description('Remove all jmw local state')
+    .option('--include-audit', 'Also remove the audit log read by jmw history')
+    .action(resetState);
+
+  program
+    .command('cache')
+    .description('Manage jmw caches')
+    .command('clear')
+    .description('Remove all jmw local state')
+    .option('--include-audit', 'Also remove the audit log read by jmw history')
+    .action(resetState);
+}
+
+async function resetState(options) {
+  try {
+    const stateDir = getStateDir();
+    const stateOptions = { auditLog: getAuditLog(), includeAudit: options.includeAudit };
+    const entries = listStateEntries(stateDir, stateOptions);
+
+    printSection('state', [formatDetail('dir', stateDir)]);
+
+    if (entries.length === 0) {
+      printInfo('nothing to remove');
+      return;
+    }
+
+    entries.forEach((entry) => printInfo(formatDetail('remove', entry)));
+
+    const confirmed = await confirm('jmw: remove local state?');
+    if (!confirmed) {
+      printWarning('state reset cancelled');
+      return;
+    }
+
+    const removed = clearState(stateDir, stateOptions);
+    printSuccess(`removed ${removed.length} entr${removed.length === 1 ? 'y' : 'ies'}`);
+  } catch (error) {
+    handleCommandError(error);
+  }
+}
+
+// A broken config must not make the reset delete the default audit log.
+function getAuditLog() {
+  try {
+    return loadConfig().audit_log;
+  } catch {
+    return expandPaths(config).audit_log;
+  }
+}
+
+export {
+  registerStateCommands
+};
@@ -0,0 +1,33 @@
+import { assertWildflyRoot, getWildflyConfig, resolveManagementCredentials } from '../deploy/wildfly.js';
+import { readDomainDeployments, readStandaloneDeployments } from '../deploy/status.js';
+import { showDeploymentStatus } from '../deploy/reporting.js';
+import { handleCommandError, loadDetection } from './shared.js';
+
+function registerStatusCommand(program) {
+  program
+    .command('status')
+    .description('Show what is currently deployed on the project WildFly')
+    .action(async () => {
+      try {
+        const detection = loadDetection();
+        const wildflyConfig = getWildflyConfig(detection.projectConfig);
+        assertWildflyRoot(wildflyConfig);
+
+        if (wildflyConfig.mode === 'domain') {
+          await resolveManagementCredentials(wildflyConfig);
+        }
+
+        const deployments = wildflyConfig.mode === 'standalone'
+          ? readStandaloneDeployments(wildflyConfig)
+          : readDomainDeployments(wildflyConfig);
+
+        showDeploymentStatus(wildflyConfig, deployments);
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerStatusCommand
+};
@@ -0,0 +1,21 @@
+import path from 'node:path';
+import { undeployArtifact } from '../deploy/index.js';
+import { handleCommandError, loadDetection } from './shared.js';
+
+function registerUndeployCommand(program) {
+  program
+    .command('undeploy')
+    .description('Remove a deployment from WildFly')
+    .argument('<artifact>', 'Artifact path or file name')
+    .action(async (artifact) => {
+      try {
+        await undeployArtifact(path.basename(artifact), loadDetection());
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerUndeployCommand
+};
@@ -0,0 +1,43 @@
+import path from 'node:path';
+import { getLocalTargetPaths, getWildflyConfig } from '../deploy/wildfly.js';
+import { getRemoteTargetPaths } from '../deploy/remote.js';
+import { printPlain } from '../output.js';
+import { handleCommandError, loadDetection, resolveClientSelection } from './shared.js';
+
+function registerWhereCommand(program) {
+  program
+    .command('where')
+    .description('Print the path an artifact would be deployed to')
+    .argument('<artifact>', 'Artifact path or file name')
+    .option('--remote', 'Print the remote target path for --client')
+    .option('-c, --client <name>', 'Target client for --remote')
+    .action((artifact, options) => {
+      try {
+        const detection = loadDetection();
+        const wildflyConfig = getWildflyConfig(detection.projectConfig);
+
+        if (options.remote) {
+          const clientSelection = resolveClientSelection(detection.projectConfig, options.client);
+          if (!clientSelection.clientConfig) {
+            throw new Error('--remote requires --client');
+          }
+
+          getRemoteTargetPaths(artifact, wildflyConfig, clientSelection.clientConfig, detection.module).forEach(printPlain);
+          return;
+        }
+
+        const targetPaths = getLocalTargetPaths(artifact, wildflyConfig, detection.module);
+        if (targetPaths.length === 0) {
+          throw new Error(`${path.basename(artifact)} is deployed through jboss-cli in domain mode and has no filesystem target`);
+        }
+
+        targetPaths.forEach(printPlain);
+      } catch (error) {
+        handleCommandError(error);
+      }
+    });
+}
+
+export {
+  registerWhereCommand
+};
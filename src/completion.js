@@ -0,0 +1,254 @@
+import { globbySync } from 'globby';
+import { loadConfig } from './config.js';
+
+const COMPLETION_SHELLS = ['bash', 'zsh', 'fish', 'powershell'];
+const ARTIFACT_GLOBS = ['target/*.jar', 'target/*.war', 'target/*.ear'];
+const PROJECT_OPTION = '--project';
+
+// One entry per command path ('' for jmw itself, 'config show' for nested
+// commands), read from the commander tree so new commands and flags complete
+// without touching the scripts. Commands starting with '__' stay hidden.
+function createCompletionSpec(program) {
+  const entries = [];
+
+  const visit = (command, path) => {
+    const subcommands = command.commands.filter((subcommand) => !subcommand._hidden && !subcommand.name().startsWith('__'));
+    const args = command.registeredArguments ?? command._args ?? [];
+
+    entries.push({
+      path,
+      subcommands: subcommands.map((subcommand) => subcommand.name()),
+      options: command.options.filter((option) => !option.hidden).map((option) => ({
+        long: option.long,
+        short: option.short,
+        takesValue: option.required || option.optional,
+        description: option.description
+      })),
+      artifacts: args.some((arg) => arg.name().startsWith('artifact'))
+    });
+
+    subcommands.forEach((subcommand) => visit(subcommand, path ? `${path} ${subcommand.name()}` : subcommand.name()));
+  };
+
+  visit(program, '');
+  return entries;
+}
+
+function renderCompletion(shell, spec) {
+  switch (shell) {
+    case 'bash':
+      return renderBashCompletion(spec);
+    case 'zsh':
+      return ['#compdef jmw', 'autoload -U +X bashcompinit && bashcompinit', renderBashCompletion(spec)].join('\n');
+    case 'fish':
+      return renderFishCompletion(spec);
+    case 'powershell':
+      return renderPowerShellCompletion(spec);
+    default:
+      throw new Error(`Unsupported shell '${shell}'. Expected one of: ${COMPLETION_SHELLS.join(', ')}`);
+  }
+}
+
+function getFlags(entry) {
+  return [...entry.options.flatMap((option) => [option.long, option.short].filter(Boolean)), '--help'];
+}
+
+// Short flags differ per command (`build -p` is --profile), so the flags that
+// take a project name are looked up by command path.
+function getProjectFlags(entry) {
+  return entry.options
+    .filter((option) => option.long === PROJECT_OPTION)
+    .flatMap((option) => [option.long, option.short].filter(Boolean));
+}
+
+function renderBashCompletion(spec) {
+  const valueFlags = [...new Set(spec.flatMap((entry) => entry.options)
+    .filter((option) => option.takesValue)
+    .flatMap((option) => [option.long, option.short].filter(Boolean)))];
+  const caseLines = (select) => spec.map((entry) => `    '${entry.path}') echo "${select(entry).join(' ')}" ;;`).join('\n');
+
+  return `# jmw bash completion; load with: source <(jmw completion bash)
+_jmw_subcommands() {
+  case "$1" in
+${caseLines((entry) => entry.subcommands)}
+  esac
+}
+
+_jmw_options() {
+  case "$1" in
+${caseLines(getFlags)}
+  esac
+}
+
+_jmw_project_flags() {
+  case "$1" in
+${caseLines(getProjectFlags)}
+  esac
+}
+
+_jmw_takes_artifacts() {
+  case "$1" in
+    ${spec.filter((entry) => entry.artifacts).map((entry) => `'${entry.path}'`).join('|') || "''"}) return 0 ;;
+  esac
+  return 1
+}
+
+_jmw_completion() {
+  local cur prev path word i
+  cur="\${COMP_WORDS[COMP_CWORD]}"
+  prev="\${COMP_WORDS[COMP_CWORD-1]}"
+  path=""
+
+  for ((i = 1; i < COMP_CWORD; i++)); do
+    word="\${COMP_WORDS[i]}"
+    [[ "$word" == -* ]] && continue
+    case " $(_jmw_subcommands "$path") " in
+      *" $word "*) path="\${path:+$path }$word" ;;
+    esac
+  done
+
+  if [[ " $(_jmw_project_flags "$path") " == *" $prev "* ]]; then
+    COMPREPLY=($(compgen -W "$(jmw __complete projects 2>/dev/null)" -- "$cur"))
+    return
+  fi
+
+  case "$prev" in
+    ${valueFlags.join('|')})
+      COMPREPLY=($(compgen -f -- "$cur"))
+      return ;;
+  esac
+
+  if [[ "$cur" == -* ]]; then
+    COMPREPLY=($(compgen -W "$(_jmw_options "$path")" -- "$cur"))
+    return
+  fi
+
+  COMPREPLY=($(compgen -W "$(_jmw_subcommands "$path")" -- "$cur"))
+  if _jmw_takes_artifacts "$path"; then
+    COMPREPLY+=($(compgen -W "$(jmw __complete artifacts 2>/dev/null)" -- "$cur"))
+  fi
+}
+
+complete -o default -F _jmw_completion jmw
+`;
+}
+
+function quoteFish(value) {
+  return `'${String(value ?? '').replace(/\\/g, '\\\\').replace(/'/g, "\\'")}'`;
+}
+
+// fish only sees which subcommands appeared, so nested commands are matched by
+// their own name and the one of their parent.
+function getFishCondition(path) {
+  if (!path) {
+    return '__fish_use_subcommand';
+  }
+
+  return path.split(' ').map((name) => `__fish_seen_subcommand_from ${name}`).join('; and ');
+}
+
+function renderFishCompletion(spec) {
+  const lines = ['# jmw fish completion; load with: jmw completion fish | source', 'complete -c jmw -f'];
+
+  for (const entry of spec) {
+    const condition = quoteFish(getFishCondition(entry.path));
+
+    if (entry.subcommands.length > 0) {
+      lines.push(`complete -c jmw -n ${condition} -a ${quoteFish(entry.subcommands.join(' '))}`);
+    }
+
+    if (entry.artifacts) {
+      lines.push(`complete -c jmw -n ${condition} -a '(jmw __complete artifacts 2>/dev/null)' -F`);
+    }
+
+    for (const option of entry.options) {
+      const flags = [
+        option.long ? `-l ${option.long.replace(/^--/, '')}` : '',
+        option.short ? `-s ${option.short.replace(/^-/, '')}` : ''
+      ].filter(Boolean).join(' ');
+      const value = option.long === PROJECT_OPTION
+        ? ` -x -a '(jmw __complete projects 2>/dev/null)'`
+        : option.takesValue ? ' -r -F' : '';
+
+      lines.push(`complete -c jmw -n ${condition} ${flags}${value} -d ${quoteFish(option.description)}`);
+    }
+  }
+
+  return `${lines.join('\n')}\n`;
+}
+
+function quotePowerShell(value) {
+  return `'${String(value).replace(/'/g, "''")}'`;
+}
+
+function renderPowerShellCompletion(spec) {
+  const table = (select) => spec
+    .map((entry) => `    ${quotePowerShell(entry.path)} = @(${select(entry).map(quotePowerShell).join(', ')})`)
+    .join('\n');
+
+  return `# jmw PowerShell completion; load with: jmw completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName jmw -ScriptBlock {
+  param($wordToComplete, $commandAst, $cursorPosition)
+
+  $subcommands = @{
+${table((entry) => entry.subcommands)}
+  }
+  $options = @{
+${table(getFlags)}
+  }
+  $projectFlags = @{
+${table(getProjectFlags)}
+  }
+  $artifactPaths = @(${spec.filter((entry) => entry.artifacts).map((entry) => quotePowerShell(entry.path)).join(', ')})
+
+  $path = ''
+  $previous = ''
+  foreach ($element in ($commandAst.CommandElements | Select-Object -Skip 1 | Where-Object { $_.Extent.EndOffset -lt $cursorPosition })) {
+    $word = $element.ToString()
+    if (-not $word.StartsWith('-') -and $subcommands[$path] -contains $word) {
+      $path = "$path $word".Trim()
+    }
+    $previous = $word
+  }
+
+  if ($projectFlags[$path] -contains $previous) {
+    $candidates = @(jmw __complete projects 2>$null)
+  } elseif ($wordToComplete.StartsWith('-')) {
+    $candidates = $options[$path]
+  } else {
+    $candidates = @($subcommands[$path])
+    if ($artifactPaths -contains $path) {
+      $candidates += @(jmw __complete artifacts 2>$null)
+    }
+  }
+
+  $candidates | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+    [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+  }
+}
+`;
+}
+
+// Values for the dynamic parts of the scripts; a broken config must not make
+// completion print errors, so failures complete nothing.
+function listCompletionValues(kind, cwd = process.cwd()) {
+  try {
+    switch (kind) {
+      case 'artifacts':
+        return globbySync(ARTIFACT_GLOBS, { cwd, onlyFiles: true }).sort();
+      case 'projects':
+        return Object.keys(loadConfig(cwd).projects || {}).sort();
+      default:
+        return [];
+    }
+  } catch {
+    return [];
+  }
+}
+
+export {
+  COMPLETION_SHELLS,
+  createCompletionSpec,
+  renderCompletion,
+  listCompletionValues
+};
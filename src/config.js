@@ -1,6 +1,18 @@
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { findUpSync } from 'find-up';
 import untildify from 'untildify';
+import { getRuleMatcher } from './build/restart.js';
+import { JmwError } from './errors.js';
+import { printDebug, printWarning } from './output.js';
+
+// Bump when a field is renamed or changes meaning, and add the step that
+// upgrades the previous version to CONFIG_MIGRATIONS.
+const CONFIG_VERSION = 2;
 
 const config = {
+  version: CONFIG_VERSION,
   projects: {
     sinfomar: {
       java_version: 17,
@@ -14,7 +26,8 @@ const config = {
       skip_tests: true,
       wildfly_root: '~/ApplicationServer/wildfly-sinfomar',
       wildfly_mode: 'domain',
-      server_group: 'other-server-group',
+      server_groups: ['other-server-group'],
+      connect_timeout: '10s',
       clients: {
         trieste: {
           host: 'TEST-SINFOMAR-TRIESTE-111',
@@ -40,6 +53,9 @@ const config = {
       skip_tests: true,
       wildfly_root: '~/ApplicationServer/wildfly-mto-3_0',
       wildfly_mode: 'standalone',
+      skip_deploy_marker: false,
+      in_flight_timeout: '30s',
+      warmup_urls: [],
       clients: {
         metro: {
           host: 'TEST-MTO-METROCARGO-101',
@@ -56,9 +72,14 @@ const config = {
       },
       global_modules: {
         EJBMtoRemote: 'modules/ejbmto/main'
+      },
+      restart_overrides: {
+        required: [],
+        'not-required': []
       }
     }
   },
+  audit_log: '~/.local/state/jmw/deploy-%Y-%m.log',
   restart_rules: {
     patterns: [
       {
@@ -76,12 +97,328 @@ const config = {
         reason: 'EJB implementation change',
         severity: 'recommended'
       }
-    ]
+    ],
+    ignore: ['target/', '.git/', 'node_modules/', '.idea/']
   }
 };
 
-function loadConfig() {
-  return expandPaths(cloneConfig(config));
+const RULE_SEVERITIES = ['none', 'recommended', 'required'];
+const CONFIG_FILE_NAME = 'jmw.config.json';
+
+let configPath = null;
+
+function setConfigPath(filePath) {
+  configPath = filePath || null;
+}
+
+function loadConfig(cwd = process.cwd(), env = process.env) {
+  const rootDirs = {};
+  const loaded = resolveConfigFiles(cwd, env).reduce((merged, filePath) => {
+    const fileConfig = readVersionedConfigFile(filePath);
+
+    for (const [projectName, projectConfig] of Object.entries(fileConfig.projects || {})) {
+      if (projectConfig?.wildfly_root !== undefined) {
+        rootDirs[projectName] = path.dirname(filePath);
+      }
+    }
+
+    return mergeConfig(merged, fileConfig);
+  }, config);
+
+  return validateConfig(resolveProjectPaths(expandPaths(expandEnvironment(cloneConfig(loaded), env)), rootDirs));
+}
+
+const ENV_PROJECT_KEYS = ['base_path', 'wildfly_root', 'log_dir', 'server_groups', 'restart_cmd', 'management_user'];
+const ENV_CLIENT_KEYS = ['host', 'user', 'wildfly_path', 'log_dir', 'restart_cmd'];
+
+// Only per-developer fields are expanded; regexes in restart rules keep
+// their literal `$`, and so do passwords and key paths, which may contain one.
+function expandEnvironment(loadedConfig, env = process.env) {
+  for (const [projectName, projectConfig] of Object.entries(loadedConfig.projects || {})) {
+    expandKeys(projectConfig, ENV_PROJECT_KEYS, `projects.${projectName}`, env);
+
+    for (const [clientName, clientConfig] of Object.entries(projectConfig.clients || {})) {
+      expandKeys(clientConfig, ENV_CLIENT_KEYS, `projects.${projectName}.clients.${clientName}`, env);
+    }
+  }
+
+  return loadedConfig;
+}
+
+function expandKeys(target, keys, location, env) {
+  for (const key of keys) {
+    if (typeof target[key] === 'string') {
+      target[key] = expandEnvValue(target[key], `${location}.${key}`, env);
+    } else if (Array.isArray(target[key])) {
+      target[key] = target[key].map((value, index) => (typeof value === 'string'
+        ? expandEnvValue(value, `${location}.${key}[${index}]`, env)
+        : value));
+    }
+  }
+}
+
+// Expands ${VAR} and $VAR; `$$` is a literal `$`. A missing variable is an
+// error so it can never turn into an empty or relative path.
+function expandEnvValue(value, location, env = process.env) {
+  return value.replace(/\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)/g, (match, braced, bare) => {
+    if (match === '$$') {
+      return '$';
+    }
+
+    const name = braced || bare;
+    if (env[name] === undefined) {
+      throw new JmwError(`${location} references undefined environment variable ${name}`, {
+        code: 'CONFIG_INVALID',
+        phase: 'config'
+      });
+    }
+
+    return env[name];
+  });
+}
+
+// Layers from lowest to highest precedence, on top of the built-in config:
+// the per-user config, then the project (or explicitly given) config.
+function resolveConfigFiles(cwd = process.cwd(), env = process.env) {
+  const userConfigPath = resolveUserConfigPath(env);
+  const projectConfigPath = resolveConfigPath(cwd, env);
+
+  printDebug(userConfigPath ? `user config ${userConfigPath}` : 'no user config in XDG_CONFIG_HOME or ~/.config/jmw');
+  printDebug(projectConfigPath ? `project config ${projectConfigPath}` : `no ${CONFIG_FILE_NAME} above ${cwd}, using built-in projects`);
+  printDebug(`config precedence ${describeConfigLayers(userConfigPath, projectConfigPath)}`);
+
+  return [userConfigPath, projectConfigPath].filter(Boolean);
+}
+
+// The layers in effect, the one that wins first.
+function describeConfigLayers(userConfigPath, projectConfigPath) {
+  return [
+    projectConfigPath && `project ${projectConfigPath}`,
+    userConfigPath && `user ${userConfigPath}`,
+    'built-in'
+  ].filter(Boolean).join(' > ');
+}
+
+function getUserConfigCandidates(env = process.env) {
+  return [
+    env.XDG_CONFIG_HOME && path.join(env.XDG_CONFIG_HOME, 'jmw', 'config.json'),
+    path.join(os.homedir(), '.config', 'jmw', 'config.json')
+  ].filter(Boolean);
+}
+
+function resolveUserConfigPath(env = process.env) {
+  return getUserConfigCandidates(env).find((candidate) => fs.existsSync(candidate)) || null;
+}
+
+// Raised when cwd is in none of the built-in projects and no config file
+// exists that could add one: names every location looked at, in the order
+// that decides which file wins.
+function createConfigNotFoundError(cwd = process.cwd(), env = process.env) {
+  const projectCandidates = [];
+  for (let directory = path.resolve(cwd); ; directory = path.dirname(directory)) {
+    projectCandidates.push(path.join(directory, CONFIG_FILE_NAME));
+    if (path.dirname(directory) === directory) {
+      break;
+    }
+  }
+
+  return new JmwError([
+    `No config file found and no built-in project contains ${path.resolve(cwd)}. Searched:`,
+    ...[...projectCandidates, ...getUserConfigCandidates(env)].map((candidate) => `  ${candidate}`),
+    `Precedence: --config, then JMW_CONFIG, then the nearest ${CONFIG_FILE_NAME}, over $XDG_CONFIG_HOME/jmw/config.json or ~/.config/jmw/config.json, over the built-in projects`
+  ].join('\n'), {
+    code: 'CONFIG_NOT_FOUND',
+    phase: 'config'
+  });
+}
+
+// --config wins over JMW_CONFIG, which wins over the nearest jmw.config.json
+// above cwd; without any of them the built-in config is used as is.
+function resolveConfigPath(cwd = process.cwd(), env = process.env) {
+  const explicit = configPath
+    ? { filePath: configPath, source: '--config' }
+    : env.JMW_CONFIG
+      ? { filePath: env.JMW_CONFIG, source: 'JMW_CONFIG' }
+      : null;
+
+  if (!explicit) {
+    return findUpSync(CONFIG_FILE_NAME, { cwd }) || null;
+  }
+
+  const filePath = path.resolve(cwd, untildify(explicit.filePath));
+  if (!fs.existsSync(filePath)) {
+    throw new JmwError(`Config file from ${explicit.source} not found: ${filePath}`, {
+      code: 'CONFIG_NOT_FOUND',
+      phase: 'config'
+    });
+  }
+
+  return filePath;
+}
+
+function readConfigFile(filePath) {
+  try {
+    return JSON.parse(fs.readFileSync(filePath, 'utf8'));
+  } catch (error) {
+    throw new JmwError(`Could not read config file ${filePath}: ${error.message}`, {
+      code: 'CONFIG_INVALID',
+      phase: 'config'
+    });
+  }
+}
+
+// Older files are still loaded, upgraded in memory, so a renamed field keeps
+// its meaning until the file itself is migrated.
+function readVersionedConfigFile(filePath) {
+  const fileConfig = readConfigFile(filePath);
+  const version = getConfigVersion(fileConfig, filePath);
+
+  if (version === CONFIG_VERSION) {
+    return fileConfig;
+  }
+
+  printWarning(`${filePath} uses config version ${version}, current is ${CONFIG_VERSION}; run 'jmw config migrate' to upgrade it`);
+  return migrateConfig(fileConfig).config;
+}
+
+// Files written before `version` existed are version 1. A newer version is an
+// error: its fields may mean something this jmw does not know about.
+function getConfigVersion(fileConfig, filePath) {
+  const version = fileConfig.version ?? 1;
+
+  if (!Number.isInteger(version) || version < 1) {
+    throw new JmwError(`${filePath}: version must be a positive integer, got ${JSON.stringify(version)}`, {
+      code: 'CONFIG_INVALID',
+      phase: 'config'
+    });
+  }
+
+  if (version > CONFIG_VERSION) {
+    throw new JmwError(`${filePath} uses config version ${version}, but this jmw only supports up to ${CONFIG_VERSION}. Update jmw`, {
+      code: 'CONFIG_INVALID',
+      phase: 'config'
+    });
+  }
+
+  return version;
+}
+
+const CONFIG_MIGRATIONS = {
+  2: migrateServerGroups
+};
+
+// Applies each step after the file's version and returns the upgraded copy
+// with one line per change made.
+function migrateConfig(fileConfig, filePath = 'config') {
+  const fromVersion = getConfigVersion(fileConfig, filePath);
+  const { version, ...rest } = cloneConfig(fileConfig);
+  const changes = [];
+
+  for (let step = fromVersion + 1; step <= CONFIG_VERSION; step += 1) {
+    changes.push(...CONFIG_MIGRATIONS[step](rest));
+  }
+
+  return {
+    config: { version: CONFIG_VERSION, ...rest },
+    fromVersion,
+    changes
+  };
+}
+
+// Version 2 replaced the single server_group with the server_groups list.
+function migrateServerGroups(fileConfig) {
+  const changes = [];
+
+  for (const [projectName, projectConfig] of Object.entries(fileConfig.projects || {})) {
+    if (!isPlainObject(projectConfig) || projectConfig.server_group === undefined) {
+      continue;
+    }
+
+    if (projectConfig.server_groups === undefined) {
+      projectConfig.server_groups = [projectConfig.server_group];
+      changes.push(`projects.${projectName}.server_group -> server_groups`);
+    } else {
+      changes.push(`projects.${projectName}.server_group removed (server_groups is set)`);
+    }
+
+    delete projectConfig.server_group;
+  }
+
+  return changes;
+}
+
+// Objects merge key by key; arrays and scalars from the override replace.
+function mergeConfig(base, override) {
+  if (!isPlainObject(base) || !isPlainObject(override)) {
+    return override === undefined ? base : override;
+  }
+
+  const merged = { ...base };
+  for (const [key, value] of Object.entries(override)) {
+    merged[key] = mergeConfig(base[key], value);
+  }
+
+  return merged;
+}
+
+function isPlainObject(value) {
+  return value !== null && typeof value === 'object' && !Array.isArray(value);
+}
+
+// A rule that fails to compile would otherwise never match, silently
+// disabling the restart check it was written for.
+function validateConfig(loadedConfig) {
+  const patterns = loadedConfig.restart_rules?.patterns || [];
+
+  patterns.forEach((rule, index) => {
+    const location = `restart_rules.patterns[${index}]`;
+
+    try {
+      getRuleMatcher(rule.match);
+    } catch (error) {
+      throw new JmwError(`${location}.match '${rule.match}' is neither a glob nor a regular expression: ${error.message}`, {
+        code: 'CONFIG_INVALID',
+        phase: 'config'
+      });
+    }
+
+    if (!RULE_SEVERITIES.includes(rule.severity)) {
+      throw new JmwError(`${location}.severity '${rule.severity}' is invalid. Expected one of: ${RULE_SEVERITIES.join(', ')}`, {
+        code: 'CONFIG_INVALID',
+        phase: 'config'
+      });
+    }
+  });
+
+  for (const [projectName, projectConfig] of Object.entries(loadedConfig.projects || {})) {
+    const groups = projectConfig.server_groups;
+    if (groups !== undefined && (!Array.isArray(groups) || groups.some((group) => typeof group !== 'string' || !group))) {
+      throw new JmwError(`projects.${projectName}.server_groups must be a list of server group names`, {
+        code: 'CONFIG_INVALID',
+        phase: 'config'
+      });
+    }
+  }
+
+  return loadedConfig;
+}
+
+// A relative wildfly_root is relative to the directory of the config file
+// that set it (rootDirs, by project), never to the cwd; base_path is the
+// fallback for configs built in code.
+function resolveProjectPaths(loadedConfig, rootDirs = {}) {
+  for (const [projectName, projectConfig] of Object.entries(loadedConfig.projects || {})) {
+    const rootDir = rootDirs[projectName] ?? projectConfig.base_path;
+    if (projectConfig.wildfly_root && rootDir && !path.isAbsolute(projectConfig.wildfly_root)) {
+      projectConfig.wildfly_root = path.resolve(rootDir, projectConfig.wildfly_root);
+    }
+
+    if (projectConfig.log_dir && projectConfig.wildfly_root && !path.isAbsolute(projectConfig.log_dir)) {
+      projectConfig.log_dir = path.resolve(projectConfig.wildfly_root, projectConfig.log_dir);
+    }
+  }
+
+  return loadedConfig;
 }
 
 function cloneConfig(value) {
@@ -104,6 +441,22 @@ function expandPaths(obj) {
   );
 }
 
+const SECRET_KEY_PATTERN = /password|passphrase/i;
+
+// For printing the effective config: secrets keep their key so it is visible
+// that they are set.
+function redactConfig(value) {
+  if (Array.isArray(value)) return value.map(redactConfig);
+  if (!isPlainObject(value)) return value;
+
+  return Object.fromEntries(
+    Object.entries(value).map(([key, entry]) => [
+      key,
+      SECRET_KEY_PATTERN.test(key) && typeof entry === 'string' ? '****' : redactConfig(entry)
+    ])
+  );
+}
+
 function getClientConfig(project, clientName) {
   if (!clientName) return null;
 
@@ -117,9 +470,26 @@ function getClientConfig(project, clientName) {
 
 export {
   config,
+  CONFIG_FILE_NAME,
+  CONFIG_VERSION,
+  setConfigPath,
   loadConfig,
+  resolveConfigPath,
+  resolveUserConfigPath,
+  resolveConfigFiles,
+  describeConfigLayers,
+  createConfigNotFoundError,
+  readConfigFile,
+  getConfigVersion,
+  migrateConfig,
+  mergeConfig,
+  expandEnvironment,
+  expandEnvValue,
+  validateConfig,
   getClientConfig,
-  expandPaths
+  redactConfig,
+  expandPaths,
+  resolveProjectPaths
 };
 
 export default config;
\ No newline at end of file
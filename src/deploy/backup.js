@@ -0,0 +1,91 @@
+import fs from 'node:fs';
+import path from 'node:path';
+
+const BACKUP_DIR_NAME = '.jmw-backups';
+const DEFAULT_BACKUP_COUNT = 5;
+
+// Deployments are backed up next to the deployments directory. Global
+// modules go to <wildfly_root>/.jmw-backups/modules/..., since WildFly loads
+// whatever lies in the modules tree.
+function getBackupDir(targetPath, wildflyRoot = null) {
+  if (wildflyRoot) {
+    const relativeDir = path.relative(wildflyRoot, path.dirname(targetPath));
+    if (relativeDir.split(path.sep)[0] === 'modules') {
+      return path.join(wildflyRoot, BACKUP_DIR_NAME, relativeDir);
+    }
+  }
+
+  return path.join(path.dirname(path.dirname(targetPath)), BACKUP_DIR_NAME);
+}
+
+// Millisecond resolution, so two deploys in the same second keep both copies.
+function formatBackupTimestamp(date = new Date()) {
+  const pad = (value, length = 2) => String(value).padStart(length, '0');
+  return `${date.getFullYear()}${pad(date.getMonth() + 1)}${pad(date.getDate())}-` +
+    `${pad(date.getHours())}${pad(date.getMinutes())}${pad(date.getSeconds())}-${pad(date.getMilliseconds(), 3)}`;
+}
+
+function listBackups(targetPath, wildflyRoot = null) {
+  const backupDir = getBackupDir(targetPath, wildflyRoot);
+  const prefix = `${path.basename(targetPath)}.bak.`;
+
+  if (!fs.existsSync(backupDir)) {
+    return [];
+  }
+
+  // Timestamps sort lexically, newest last.
+  return fs.readdirSync(backupDir)
+    .filter((entry) => entry.startsWith(prefix))
+    .sort()
+    .map((entry) => path.join(backupDir, entry));
+}
+
+function createBackup(targetPath, keep = DEFAULT_BACKUP_COUNT, { date = new Date(), wildflyRoot = null } = {}) {
+  if (keep <= 0 || !fs.existsSync(targetPath)) {
+    return null;
+  }
+
+  const backupDir = getBackupDir(targetPath, wildflyRoot);
+  const baseName = `${path.basename(targetPath)}.bak.${formatBackupTimestamp(date)}`;
+
+  // Copy rather than move: removing the deployed file would make the
+  // scanner undeploy it before the new copy lands. A name that is already
+  // taken within the same millisecond gets a counter instead of overwriting.
+  fs.mkdirSync(backupDir, { recursive: true });
+  let backupPath = path.join(backupDir, baseName);
+  for (let counter = 1; ; counter += 1) {
+    try {
+      fs.copyFileSync(targetPath, backupPath, fs.constants.COPYFILE_EXCL);
+      break;
+    } catch (error) {
+      if (error.code !== 'EEXIST') {
+        throw error;
+      }
+      backupPath = path.join(backupDir, `${baseName}-${counter}`);
+    }
+  }
+  pruneBackups(targetPath, keep, wildflyRoot);
+
+  return backupPath;
+}
+
+function pruneBackups(targetPath, keep = DEFAULT_BACKUP_COUNT, wildflyRoot = null) {
+  const backups = listBackups(targetPath, wildflyRoot);
+  const removed = backups.slice(0, Math.max(0, backups.length - keep));
+
+  removed.forEach((backupPath) => fs.rmSync(backupPath, { force: true }));
+  return removed;
+}
+
+function findLatestBackup(targetPath, wildflyRoot = null) {
+  return listBackups(targetPath, wildflyRoot).pop() || null;
+}
+
+export {
+  DEFAULT_BACKUP_COUNT,
+  getBackupDir,
+  listBackups,
+  createBackup,
+  pruneBackups,
+  findLatestBackup
+};
@@ -0,0 +1,87 @@
+import { execFileSync } from 'node:child_process';
+import { JmwError } from '../errors.js';
+import { getCliInvocation } from './wildfly.js';
+import { formatCommand, printDebug } from '../output.js';
+
+const COMPARATOR_PATTERN = /^(>=|<=|>|<|=|\^|~)?v?(\d+(?:\.\d+){0,2})(?:\.x)?$/;
+
+function readCliVersion(cliPath, run = execFileSync) {
+  const { command, args } = getCliInvocation(cliPath, ['--version']);
+  printDebug(`exec ${formatCommand(command, args)}`);
+  const output = run(command, args, { stdio: 'pipe', encoding: 'utf8' });
+  const version = parseCliVersion(output);
+
+  if (!version) {
+    throw new Error(`Could not read the jboss-cli version from ${cliPath} --version`);
+  }
+
+  return version;
+}
+
+function parseCliVersion(output) {
+  const match = /(?:Release|Product|WildFly)[^\d\n]*(\d+(?:\.\d+){0,2})/i.exec(String(output));
+  return match ? match[1] : null;
+}
+
+function satisfiesVersion(version, constraint) {
+  return constraint.trim().split(/\s+/).every((comparator) => satisfiesComparator(version, comparator));
+}
+
+function satisfiesComparator(version, comparator) {
+  const match = COMPARATOR_PATTERN.exec(comparator);
+  if (!match) {
+    throw new Error(`Invalid require_cli_version constraint '${comparator}'`);
+  }
+
+  const [, operator = '', expected] = match;
+  const actualParts = toParts(version);
+  const expectedParts = toParts(expected);
+  const order = compareParts(actualParts, expectedParts);
+  const precision = expected.split('.').length;
+
+  switch (operator) {
+    case '>=': return order >= 0;
+    case '>': return order > 0;
+    case '<=': return order <= 0;
+    case '<': return order < 0;
+    case '^': return order >= 0 && actualParts[0] === expectedParts[0];
+    case '~': return order >= 0 && actualParts[0] === expectedParts[0] && actualParts[1] === expectedParts[1];
+    default: return compareParts(actualParts.slice(0, precision), expectedParts.slice(0, precision)) === 0;
+  }
+}
+
+function toParts(version) {
+  const parts = version.split('.').map(Number);
+  return [parts[0] || 0, parts[1] || 0, parts[2] || 0];
+}
+
+function compareParts(left, right) {
+  for (let index = 0; index < Math.max(left.length, right.length); index++) {
+    const difference = (left[index] || 0) - (right[index] || 0);
+    if (difference !== 0) {
+      return Math.sign(difference);
+    }
+  }
+
+  return 0;
+}
+
+function assertCliVersion(cliPath, constraint, run = execFileSync) {
+  const version = readCliVersion(cliPath, run);
+
+  if (!satisfiesVersion(version, constraint)) {
+    throw new JmwError(`jboss-cli version ${version} does not satisfy require_cli_version '${constraint}' (${cliPath})`, {
+      code: 'CLI_VERSION_MISMATCH',
+      phase: 'validate'
+    });
+  }
+
+  return version;
+}
+
+export {
+  readCliVersion,
+  parseCliVersion,
+  satisfiesVersion,
+  assertCliVersion
+};
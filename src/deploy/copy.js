@@ -0,0 +1,69 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import { createHash } from 'node:crypto';
+import { Transform } from 'node:stream';
+import { pipeline } from 'node:stream/promises';
+import { JmwError } from '../errors.js';
+
+// WildFly often runs as another user, so artifacts are always at least
+// readable by everyone; the source's other bits (e.g. execute) are kept.
+const ARTIFACT_MODE = 0o644;
+
+async function copyArtifact(source, dest, onProgress = () => {}, { preserveTimestamps = true } = {}) {
+  const sourceStats = fs.statSync(source);
+  const totalBytes = sourceStats.size;
+  const sourceHash = createHash('sha256');
+  let copiedBytes = 0;
+
+  const progress = new Transform({
+    transform(chunk, _encoding, callback) {
+      copiedBytes += chunk.length;
+      sourceHash.update(chunk);
+      onProgress({ copiedBytes, totalBytes });
+      callback(null, chunk);
+    }
+  });
+
+  await pipeline(fs.createReadStream(source), progress, fs.createWriteStream(dest, { mode: ARTIFACT_MODE }));
+  // The mode option only applies when dest is created.
+  fs.chmodSync(dest, (sourceStats.mode & 0o777) | ARTIFACT_MODE);
+  if (preserveTimestamps) {
+    fs.utimesSync(dest, sourceStats.atime, sourceStats.mtime);
+  }
+  syncFile(dest);
+
+  // Read the destination back so truncated writes on network filesystems
+  // are caught before WildFly sees the artifact.
+  const checksum = sourceHash.digest('hex');
+  const destChecksum = await hashFile(dest);
+  if (destChecksum !== checksum) {
+    throw new JmwError(`Checksum mismatch after copying ${path.basename(source)} to ${dest} (sha256 ${checksum} != ${destChecksum})`, {
+      code: 'CHECKSUM_MISMATCH',
+      phase: 'deploy',
+      artifact: path.basename(source)
+    });
+  }
+
+  return { copiedBytes, totalBytes, checksum };
+}
+
+// Flush to disk before the scanner is told to deploy the file.
+function syncFile(filePath) {
+  const fd = fs.openSync(filePath, 'r+');
+  try {
+    fs.fsyncSync(fd);
+  } finally {
+    fs.closeSync(fd);
+  }
+}
+
+async function hashFile(filePath) {
+  const hash = createHash('sha256');
+  await pipeline(fs.createReadStream(filePath), hash);
+  return hash.digest('hex');
+}
+
+export {
+  copyArtifact,
+  hashFile
+};
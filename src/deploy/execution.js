@@ -1,13 +1,42 @@
 import fs from 'node:fs';
 import path from 'node:path';
-import { execFileSync } from 'node:child_process';
+import { execFileSync, spawn } from 'node:child_process';
+import ms from 'ms';
+import { copyArtifact } from './copy.js';
+import { syncModuleXml } from './module-xml.js';
+import { createBackup } from './backup.js';
+import { readArtifactServerGroups } from './status.js';
+import { assertCliVersion } from './cli-version.js';
 import {
+  formatController,
+  formatServerGroups,
+  getCliInvocation,
+  getCliPath,
+  assertCliPath,
+  assertModulePath,
+  cliQuote,
+  createCliTimeoutError,
+  debugCliInvocation,
+  getConnectArgs,
+  isCliTimeout,
+  getDeploymentsDir,
+  redactCredentials
+} from './wildfly.js';
+import { LIFECYCLE_STAGES } from '../lifecycle/index.js';
+import {
+  formatCommand,
   formatDetail,
+  getCommandStdio,
+  getCommandStreams,
   joinDetails,
   printCommand,
+  printDebug,
   printInfo,
-  printSection
+  printSection,
+  printWarning
 } from '../output.js';
+import { confirm } from '../utils.js';
+import { JmwError } from '../errors.js';
 
 function createDeploymentResult() {
   return {
@@ -17,13 +46,14 @@ function createDeploymentResult() {
   };
 }
 
-function trackFileCopy(result, source, dest) {
+function trackFileCopy(result, source, dest, checksum) {
   const stats = fs.statSync(dest);
   result.actions.push({
     type: 'file_copied',
     source,
     dest,
     size: stats.size,
+    checksum,
     timestamp: new Date()
   });
 }
@@ -44,6 +74,47 @@ function trackMarkerCreated(result, markerPath) {
   });
 }
 
+function trackMarkerRemoved(result, markerPath) {
+  result.actions.push({
+    type: 'marker_removed',
+    path: markerPath,
+    timestamp: new Date()
+  });
+}
+
+function trackFileRemoved(result, filePath) {
+  result.actions.push({
+    type: 'file_removed',
+    path: filePath,
+    timestamp: new Date()
+  });
+}
+
+function trackFileWritten(result, filePath) {
+  result.actions.push({
+    type: 'file_written',
+    path: filePath,
+    timestamp: new Date()
+  });
+}
+
+function trackBackupCreated(result, source, backupPath) {
+  result.backup = backupPath;
+  result.actions.push({
+    type: 'backup_created',
+    source,
+    path: backupPath,
+    timestamp: new Date()
+  });
+}
+
+function backupExisting(destPath, wildflyConfig, result) {
+  const backupPath = createBackup(destPath, wildflyConfig.backupCount, { wildflyRoot: wildflyConfig.root });
+  if (backupPath) {
+    trackBackupCreated(result, destPath, backupPath);
+  }
+}
+
 function trackCliDeploy(result, cliPath, command) {
   result.actions.push({
     type: 'cli_deploy',
@@ -53,107 +124,711 @@ function trackCliDeploy(result, cliPath, command) {
   });
 }
 
-function executeDeploymentPlan(plan, result = createDeploymentResult()) {
+async function executeDeploymentPlan(plan, result = createDeploymentResult(), emit = noopEmit) {
+  const deployOptions = plan.deployOptions || {};
+
+  printDebug(`artifact ${path.resolve(plan.artifactPath)}`);
+  printDebug(`deploy mode ${plan.module.isGlobalModule ? 'global-module' : plan.wildflyConfig.mode}${deployOptions.alsoGlobal ? ' + also-global' : ''} · wildfly root ${plan.wildflyConfig.root}`);
+
+  if (!plan.module.isGlobalModule || deployOptions.alsoGlobal) {
+    await deployNormal(plan.artifactPath, plan.wildflyConfig, plan.module, result, emit, deployOptions);
+  }
+
   if (plan.module.isGlobalModule) {
-    deployGlobalModule(plan.artifactPath, plan.wildflyConfig, plan.module, result);
-  } else {
-    deployNormal(plan.artifactPath, plan.wildflyConfig, plan.module, result);
+    await deployGlobalModule(plan.artifactPath, plan.wildflyConfig, plan.module, result, emit, deployOptions);
   }
 
+  result.dryRun = deployOptions.dryRun === true;
+  result.endTime = new Date();
+
   return result;
 }
 
-function deployGlobalModule(artifactPath, wildflyConfig, moduleInfo, result) {
-  const modulePath = path.join(wildflyConfig.root, moduleInfo.deploymentPath);
+async function noopEmit() {}
 
-  printSection('apply deployment', [
-    formatDetail('mode', 'global-module'),
-    formatDetail('target', modulePath)
-  ]);
+async function copyWithEvents(source, dest, emit, wildflyConfig) {
+  await emit(LIFECYCLE_STAGES.COPY_STARTED, { source, dest });
+  const { checksum } = await copyArtifact(source, dest, (progress) => {
+    emit(LIFECYCLE_STAGES.COPY_PROGRESS, { source, dest, ...progress });
+  }, { preserveTimestamps: wildflyConfig.preserveTimestamps });
+
+  return checksum;
+}
+
+async function deployGlobalModule(artifactPath, wildflyConfig, moduleInfo, result, emit = noopEmit, deployOptions = {}) {
+  moduleInfo.deploymentPaths.forEach(assertModulePath);
+
+  for (const deploymentPath of moduleInfo.deploymentPaths) {
+    const modulePath = path.join(wildflyConfig.root, deploymentPath);
+    const destPath = path.join(modulePath, path.basename(artifactPath));
+    printDebug(`module target ${path.resolve(destPath)}`);
+
+    printSection('apply deployment', [
+      formatDetail('mode', 'global-module'),
+      formatDetail('target', modulePath)
+    ], { mode: 'global-module', artifact: path.basename(artifactPath), target: modulePath });
+
+    if (!fs.existsSync(modulePath)) {
+      printWarning(`module directory ${modulePath} does not exist yet; a new module directory often means a typo in global_modules`);
+    }
+
+    if (deployOptions.dryRun) {
+      if (!fs.existsSync(modulePath)) {
+        printDryRun(`create directory ${modulePath}`);
+      }
+      printDryRun(`copy ${artifactPath} -> ${destPath}`);
+      applyModuleXml(modulePath, deploymentPath, artifactPath, moduleInfo, result, deployOptions);
+      continue;
+    }
+
+    if (!fs.existsSync(modulePath)) {
+      if (!deployOptions.preflighted && !await confirm(`jmw: create module directory ${modulePath}?`)) {
+        throw new Error(`Deployment cancelled: module directory ${modulePath} not created`);
+      }
+
+      fs.mkdirSync(modulePath, { recursive: true });
+      trackDirCreated(result, modulePath);
+    }
+
+    backupExisting(destPath, wildflyConfig, result);
+    const checksum = await copyWithEvents(artifactPath, destPath, emit, wildflyConfig);
+    trackFileCopy(result, artifactPath, destPath, checksum);
+    applyModuleXml(modulePath, deploymentPath, artifactPath, moduleInfo, result, deployOptions);
+  }
+}
 
-  if (!fs.existsSync(modulePath)) {
-    fs.mkdirSync(modulePath, { recursive: true });
-    trackDirCreated(result, modulePath);
+function applyModuleXml(modulePath, deploymentPath, artifactPath, moduleInfo, result, deployOptions) {
+  if (!moduleInfo.moduleXml) {
+    return;
   }
 
-  const destPath = path.join(modulePath, path.basename(artifactPath));
-  fs.copyFileSync(artifactPath, destPath);
-  trackFileCopy(result, artifactPath, destPath);
+  const outcome = syncModuleXml(modulePath, deploymentPath, moduleInfo.moduleXml, {
+    artifactName: path.basename(artifactPath),
+    dryRun: deployOptions.dryRun
+  });
+  printDebug(`module.xml ${outcome.filePath}: ${outcome.status}`);
+
+  if (outcome.skipped.length > 0) {
+    printWarning(`${modulePath} also holds ${outcome.skipped.join(', ')}, left out of module.xml: older versions or JARs no global_modules entry deploys; remove them`);
+  }
+
+  if (outcome.status === 'hand-written') {
+    printWarning(`${outcome.filePath} was not generated by jmw and was left unchanged; remove it to let jmw maintain module ${outcome.name}`);
+  } else if (outcome.status === 'would-write') {
+    printDryRun(`write ${outcome.filePath} (module ${outcome.name})`);
+  } else if (outcome.status === 'written') {
+    trackFileWritten(result, outcome.filePath);
+  }
 }
 
-function deployNormal(artifactPath, wildflyConfig, moduleInfo, result) {
+async function deployNormal(artifactPath, wildflyConfig, moduleInfo, result, emit = noopEmit, deployOptions = {}) {
   if (wildflyConfig.mode === 'standalone') {
-    deployStandalone(artifactPath, wildflyConfig, moduleInfo, result);
+    await deployStandalone(artifactPath, wildflyConfig, moduleInfo, result, emit, deployOptions);
   } else {
-    deployDomain(artifactPath, wildflyConfig, result);
+    await deployDomain(artifactPath, wildflyConfig, result, deployOptions);
   }
 }
 
-function deployStandalone(artifactPath, wildflyConfig, _moduleInfo, result) {
-  const deploymentsDir = path.join(wildflyConfig.root, 'standalone', 'deployments');
+async function deployStandalone(artifactPath, wildflyConfig, moduleInfo, result, emit = noopEmit, deployOptions = {}) {
+  const deploymentsDir = getDeploymentsDir(wildflyConfig);
   const destPath = path.join(deploymentsDir, path.basename(artifactPath));
   const markerPath = path.join(deploymentsDir, `${path.basename(artifactPath)}.dodeploy`);
+  const skipMarkerPath = path.join(deploymentsDir, `${path.basename(artifactPath)}.skipdeploy`);
+  const useSkipMarker = deployOptions.skipDeployMarker || fs.existsSync(skipMarkerPath);
+  printDebug(`deployments dir ${path.resolve(deploymentsDir)} · skip marker ${useSkipMarker ? 'on' : 'off'}`);
 
   printSection('apply deployment', [
     formatDetail('mode', 'standalone'),
     formatDetail('target', destPath)
-  ]);
+  ], { mode: 'standalone', artifact: path.basename(artifactPath), target: destPath });
+
+  const staleDeployments = findStaleDeployments(deploymentsDir, path.basename(artifactPath), moduleInfo);
+
+  if (deployOptions.dryRun) {
+    previewStandalone(artifactPath, destPath, markerPath, useSkipMarker ? skipMarkerPath : null, deployOptions);
+    warnStaleDeployments(staleDeployments, path.basename(artifactPath));
+    return;
+  }
 
   if (!fs.existsSync(deploymentsDir)) {
     fs.mkdirSync(deploymentsDir, { recursive: true });
     trackDirCreated(result, deploymentsDir);
   }
 
-  fs.copyFileSync(artifactPath, destPath);
-  trackFileCopy(result, artifactPath, destPath);
+  await waitForInFlightMarkers(deploymentsDir, wildflyConfig.inFlightTimeout, deployOptions.concurrentArtifacts);
+  await undeployStaleDeployments(staleDeployments, path.basename(artifactPath), wildflyConfig, result, deployOptions.staleUndeploys?.[path.basename(artifactPath)]);
+
+  if (deployOptions.redeploy) {
+    await undeployStandalone(destPath, wildflyConfig.inFlightTimeout, result);
+  }
+
+  backupExisting(destPath, wildflyConfig, result);
+
+  const retries = deployOptions.retryDeploy || 0;
+  const timeout = deployOptions.timeout ?? wildflyConfig.deployTimeout;
+  for (let attempt = 1; ; attempt++) {
+    result.attempts = attempt;
+
+    if (useSkipMarker && !fs.existsSync(skipMarkerPath)) {
+      fs.writeFileSync(skipMarkerPath, '');
+      trackMarkerCreated(result, skipMarkerPath);
+    }
+
+    const checksum = await copyWithEvents(artifactPath, destPath, emit, wildflyConfig);
+    trackFileCopy(result, artifactPath, destPath, checksum);
 
-  fs.writeFileSync(markerPath, '');
-  trackMarkerCreated(result, markerPath);
+    if (useSkipMarker) {
+      fs.rmSync(skipMarkerPath, { force: true });
+      trackMarkerRemoved(result, skipMarkerPath);
+    }
+
+    fs.writeFileSync(markerPath, '');
+    trackMarkerCreated(result, markerPath);
+    await emit(LIFECYCLE_STAGES.MARKER_CREATED, { markerPath });
+
+    const outcome = await waitForDeploymentOutcome(destPath, timeout);
+    if (outcome !== 'failed' || attempt > retries) {
+      assertDeploymentOutcome(destPath, outcome, timeout, attempt);
+      return;
+    }
+
+    const failedMarker = `${destPath}.failed`;
+    printWarning(`attempt ${attempt}/${retries + 1} produced ${path.basename(failedMarker)} (${readFailureReason(failedMarker) || 'no reason given'}), retrying in ${ms(deployOptions.retryDelay)}`);
+    fs.rmSync(failedMarker, { force: true });
+    trackMarkerRemoved(result, failedMarker);
+    await new Promise((resolve) => setTimeout(resolve, deployOptions.retryDelay));
+  }
+}
+
+// Matches the version Maven appends to the finalName, e.g. '1.2.3' or
+// '1.2.4-SNAPSHOT', when the pom's artifactId does not prefix the file.
+const TRAILING_VERSION_PATTERN = /^(.+)-(\d+(?:\.\d+)*(?:[-.][A-Za-z0-9]+)*)$/;
+
+function splitArtifactName(artifactName, moduleInfo = {}) {
+  const stem = path.basename(artifactName, path.extname(artifactName));
+
+  if (moduleInfo.artifactId && stem.startsWith(`${moduleInfo.artifactId}-`)) {
+    return { artifactId: moduleInfo.artifactId, version: stem.slice(moduleInfo.artifactId.length + 1) };
+  }
+
+  const match = TRAILING_VERSION_PATTERN.exec(stem);
+  return match ? { artifactId: match[1], version: match[2] } : null;
+}
+
+// WildFly keys deployments by file name, so a version bump leaves the
+// previous EJBPcs-1.2.3.jar running next to EJBPcs-1.2.4.jar.
+function findStaleDeployments(deploymentsDir, artifactName, moduleInfo) {
+  const current = splitArtifactName(artifactName, moduleInfo);
+
+  if (!current || !fs.existsSync(deploymentsDir)) {
+    return [];
+  }
+
+  return fs.readdirSync(deploymentsDir).filter((fileName) => {
+    if (fileName === artifactName || path.extname(fileName) !== path.extname(artifactName)) {
+      return false;
+    }
+
+    const candidate = splitArtifactName(fileName, moduleInfo);
+    return candidate !== null && candidate.artifactId === current.artifactId && candidate.version !== current.version;
+  });
+}
+
+function warnStaleDeployments(staleDeployments, artifactName) {
+  for (const staleName of staleDeployments) {
+    printWarning(`${staleName} is another version of ${artifactName} and stays deployed next to it`);
+  }
+}
+
+// approved is the answer collected before a parallel deploy started; without
+// it each stale deployment is confirmed here.
+async function undeployStaleDeployments(staleDeployments, artifactName, wildflyConfig, result, approved) {
+  if (!approved) {
+    approved = await confirmStaleUndeploys(staleDeployments, artifactName);
+  }
+
+  for (const staleName of staleDeployments.filter((name) => approved.includes(name))) {
+    await undeployStandaloneArtifact(staleName, wildflyConfig, result);
+  }
+}
+
+async function confirmStaleUndeploys(staleDeployments, artifactName) {
+  warnStaleDeployments(staleDeployments, artifactName);
+
+  const approved = [];
+  for (const staleName of staleDeployments) {
+    if (await confirm(`jmw: undeploy stale ${staleName}?`)) {
+      approved.push(staleName);
+    }
+  }
+
+  return approved;
+}
+
+async function waitForDeploymentOutcome(deployedPath, timeout) {
+  const deadline = Date.now() + timeout;
+
+  // The scanner consumes .dodeploy and holds .isdeploying until it writes
+  // either .deployed or .failed, so only trust markers once both are gone.
+  while (Date.now() < deadline) {
+    const pending = fs.existsSync(`${deployedPath}.dodeploy`) || fs.existsSync(`${deployedPath}.isdeploying`);
+    printDebug(`poll ${path.basename(deployedPath)}: ${pending ? 'scanner busy' : 'scanner idle'}, ${ms(Math.max(0, deadline - Date.now()))} left`);
+
+    if (!pending && fs.existsSync(`${deployedPath}.failed`)) {
+      return 'failed';
+    }
+
+    if (!pending && fs.existsSync(`${deployedPath}.deployed`)) {
+      return 'deployed';
+    }
+
+    await new Promise((resolve) => setTimeout(resolve, IN_FLIGHT_POLL_INTERVAL));
+  }
+
+  return 'inconclusive';
 }
 
-function deployDomain(artifactPath, wildflyConfig, result) {
+function assertDeploymentOutcome(deployedPath, outcome, timeout, attempt) {
+  const artifactName = path.basename(deployedPath);
+  const attempts = attempt > 1 ? ` after ${attempt} attempts` : '';
+
+  if (outcome === 'failed') {
+    const reason = readFailureReason(`${deployedPath}.failed`);
+    throw new JmwError(`WildFly failed to deploy ${artifactName}${attempts}: ${reason || `see ${artifactName}.failed and server.log`}`, {
+      code: 'DEPLOY_FAILED',
+      phase: 'deploy',
+      artifact: artifactName
+    });
+  }
+
+  if (outcome === 'inconclusive') {
+    throw new JmwError(`No .deployed or .failed marker for ${artifactName} within ${ms(timeout)}`, {
+      code: 'DEPLOY_INCONCLUSIVE',
+      phase: 'deploy',
+      artifact: artifactName
+    });
+  }
+
+  printInfo(joinDetails([
+    formatDetail('marker', `${artifactName}.deployed`),
+    attempt > 1 ? formatDetail('attempt', attempt) : ''
+  ]), { phase: 'deploy', artifact: artifactName, marker: `${artifactName}.deployed`, attempt });
+}
+
+async function deployDomain(artifactPath, wildflyConfig, result, deployOptions = {}) {
   const artifactName = path.basename(artifactPath);
-  const cliPath = path.join(wildflyConfig.root, 'bin', 'jboss-cli.sh');
+  const cliPath = getCliPath(wildflyConfig);
+
+  if (deployOptions.allRelevantServerGroups) {
+    resolveRelevantServerGroups(artifactName, wildflyConfig);
+  }
 
   if (!wildflyConfig.serverGroup) {
-    throw new Error('Missing server_group in configuration for domain mode');
+    throw new Error('Missing server_groups in configuration for domain mode');
   }
 
   printSection('apply deployment', [
     formatDetail('mode', 'domain'),
-    formatDetail('group', wildflyConfig.serverGroup)
-  ]);
+    formatServerGroups(wildflyConfig),
+    formatDetail('controller', formatController(wildflyConfig.controller))
+  ], { mode: 'domain', artifact: artifactName, serverGroups: wildflyConfig.serverGroup });
   printInfo(joinDetails([
     formatDetail('artifact', artifactName),
     formatDetail('cli', cliPath)
-  ]));
+  ]), { phase: 'apply deployment', artifact: artifactName });
+
+  assertCliPath(wildflyConfig);
 
-  if (!fs.existsSync(cliPath)) {
-    throw new Error(`jboss-cli.sh not found: ${cliPath}`);
+  const deployCommand = `deploy ${cliQuote(artifactPath)} --name=${cliQuote(artifactName)} --runtime-name=${cliQuote(artifactName)} --server-groups=${wildflyConfig.serverGroup}`;
+  const undeployCommand = `undeploy ${cliQuote(artifactName)} --server-groups=${wildflyConfig.serverGroup}`;
+  const connectArgs = getConnectArgs(wildflyConfig);
+  const cliTimeout = deployOptions.timeout ?? wildflyConfig.cliTimeout;
+
+  if (deployOptions.dryRun) {
+    const displayArgs = getConnectArgs(wildflyConfig, { redact: true });
+    printDryRun('run jboss-cli');
+    printCommand(formatCommand(cliPath, [...displayArgs, `--commands=${undeployCommand}`]));
+    printCommand(formatCommand(cliPath, [...displayArgs, `--commands=${deployCommand}`]));
+    return;
   }
 
-  const deployCommand = `deploy ${artifactPath} --name=${artifactName} --runtime-name=${artifactName} --server-groups=${wildflyConfig.serverGroup}`;
-  const undeployCommand = `undeploy ${artifactName} --server-groups=${wildflyConfig.serverGroup}`;
+  if (wildflyConfig.requireCliVersion) {
+    const cliVersion = assertCliVersion(cliPath, wildflyConfig.requireCliVersion);
+    printInfo(joinDetails([
+      formatDetail('cli version', cliVersion),
+      formatDetail('required', wildflyConfig.requireCliVersion)
+    ]));
+  }
 
   printInfo('jboss-cli deploy command');
   printCommand(deployCommand);
 
+  if (deployOptions.preUndeployVerify) {
+    showCurrentDeployments(cliPath, wildflyConfig);
+  }
+
+  if (deployOptions.step) {
+    const confirmed = await confirm(`jmw: undeploy ${artifactName} from ${wildflyConfig.serverGroups.join(', ')}?`);
+    if (!confirmed) {
+      throw new Error('Deployment cancelled before undeploy');
+    }
+  }
+
   try {
     try {
-      execFileSync(cliPath, ['--connect', `--commands=${undeployCommand}`], {
-        stdio: 'inherit'
+      const undeploy = getCliInvocation(cliPath, [...connectArgs, `--commands=${undeployCommand}`]);
+      debugCliInvocation(undeploy, wildflyConfig);
+      execFileSync(undeploy.command, undeploy.args, {
+        stdio: 'pipe',
+        encoding: 'utf8',
+        timeout: cliTimeout,
+        killSignal: 'SIGKILL'
       });
-    } catch {
-      // Ignore undeploy failures.
+    } catch (error) {
+      if (isCliTimeout(error)) {
+        throw createCliTimeoutError('undeploy', cliTimeout, { phase: 'deploy', artifact: artifactName });
+      }
+
+      const output = `${error.stdout || ''}${error.stderr || ''}`;
+
+      // A missing deployment is the normal first-deploy case.
+      if (!isNotDeployedOutput(output)) {
+        printWarning(`undeploy of ${artifactName} failed, continuing: ${redactCredentials(lastLine(output) || error.message, wildflyConfig)}`);
+      }
     }
 
-    execFileSync(cliPath, ['--connect', `--commands=${deployCommand}`], {
-      stdio: 'inherit'
-    });
+    const deploy = getCliInvocation(cliPath, [...connectArgs, `--commands=${deployCommand}`]);
+    debugCliInvocation(deploy, wildflyConfig);
+    await runCliCapturing(deploy, cliTimeout);
 
     trackCliDeploy(result, cliPath, `${undeployCommand} ; ${deployCommand}`);
   } catch (error) {
-    throw new Error(`Domain deployment failed via jboss-cli.sh: ${error.message}`);
+    if (error instanceof JmwError) {
+      throw error;
+    }
+
+    if (isCliTimeout(error)) {
+      throw createCliTimeoutError('deploy', cliTimeout, { phase: 'deploy', artifact: artifactName });
+    }
+
+    const detail = summarizeCliFailure(error.output || '') || error.message;
+    throw new JmwError(`Domain deployment failed via ${path.basename(cliPath)} (connect timeout ${ms(wildflyConfig.connectTimeout)}): ${redactCredentials(detail, wildflyConfig)}`, {
+      code: 'DEPLOY_FAILED',
+      phase: 'deploy',
+      artifact: artifactName
+    });
+  }
+}
+
+// Redeploys go to the groups that already run the artifact; a first deploy
+// falls back to the configured ones. Updates the plan's config in place so
+// the restart advice targets the same groups.
+function resolveRelevantServerGroups(artifactName, wildflyConfig) {
+  const serverGroups = readArtifactServerGroups(wildflyConfig, artifactName);
+
+  if (serverGroups.length === 0) {
+    printInfo(formatDetail('server groups', `${artifactName} is not deployed yet, using the configured groups`));
+    return wildflyConfig;
+  }
+
+  printInfo(formatDetail('server groups', `from deployment-info: ${serverGroups.join(', ')}`));
+  return Object.assign(wildflyConfig, { serverGroups, serverGroup: serverGroups.join(',') });
+}
+
+const UNDEPLOY_MARKERS = ['.deployed', '.dodeploy', '.failed', '.skipdeploy', '.undeployed'];
+
+async function undeployStandaloneArtifact(artifactName, wildflyConfig, result) {
+  const deployedPath = path.join(getDeploymentsDir(wildflyConfig), artifactName);
+  const deployedMarker = `${deployedPath}.deployed`;
+
+  printSection('apply undeploy', [
+    formatDetail('mode', 'standalone'),
+    formatDetail('target', deployedPath)
+  ], { mode: 'standalone', artifact: artifactName, target: deployedPath });
+
+  // Dropping .deployed tells the scanner to undeploy; the artifact and the
+  // leftover markers are only removed once it has written .undeployed.
+  if (fs.existsSync(deployedMarker)) {
+    fs.rmSync(`${deployedPath}.undeployed`, { force: true });
+    fs.rmSync(deployedMarker, { force: true });
+    trackMarkerRemoved(result, deployedMarker);
+    await waitForUndeployedMarker(deployedPath, wildflyConfig.deployTimeout, 'undeploy');
+  }
+
+  if (fs.existsSync(deployedPath)) {
+    fs.rmSync(deployedPath, { force: true });
+    trackFileRemoved(result, deployedPath);
+  }
+
+  for (const markerPath of UNDEPLOY_MARKERS.map((marker) => `${deployedPath}${marker}`)) {
+    if (fs.existsSync(markerPath)) {
+      fs.rmSync(markerPath, { force: true });
+      trackMarkerRemoved(result, markerPath);
+    }
+  }
+
+  result.endTime = new Date();
+  return result;
+}
+
+function undeployDomainArtifact(artifactName, wildflyConfig, result) {
+  if (!wildflyConfig.serverGroup) {
+    throw new Error('Missing server_groups in configuration for domain mode');
+  }
+
+  const cliPath = assertCliPath(wildflyConfig);
+
+  const undeployCommand = `undeploy ${cliQuote(artifactName)} --server-groups=${wildflyConfig.serverGroup}`;
+  const connectArgs = getConnectArgs(wildflyConfig);
+
+  printSection('apply undeploy', [
+    formatDetail('mode', 'domain'),
+    formatServerGroups(wildflyConfig),
+    formatDetail('controller', formatController(wildflyConfig.controller))
+  ], { mode: 'domain', artifact: artifactName, serverGroups: wildflyConfig.serverGroup });
+  printCommand(undeployCommand);
+
+  try {
+    const undeploy = getCliInvocation(cliPath, [...connectArgs, `--commands=${undeployCommand}`]);
+    debugCliInvocation(undeploy, wildflyConfig);
+    execFileSync(undeploy.command, undeploy.args, {
+      stdio: 'pipe',
+      encoding: 'utf8',
+      timeout: wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+  } catch (error) {
+    if (isCliTimeout(error)) {
+      throw createCliTimeoutError('undeploy', wildflyConfig.cliTimeout, { phase: 'undeploy', artifact: artifactName });
+    }
+
+    const output = `${error.stdout || ''}${error.stderr || ''}`;
+    throw new JmwError(`Undeploy of ${artifactName} failed via ${path.basename(cliPath)}: ${redactCredentials(summarizeCliFailure(output) || error.message, wildflyConfig)}`, {
+      code: 'UNDEPLOY_FAILED',
+      phase: 'undeploy',
+      artifact: artifactName
+    });
+  }
+
+  trackCliDeploy(result, cliPath, undeployCommand);
+  result.endTime = new Date();
+  return result;
+}
+
+// WildFly writes the failure description into the .failed marker.
+function readFailureReason(failedMarker) {
+  try {
+    return fs.readFileSync(failedMarker, 'utf8').trim();
+  } catch {
+    return '';
+  }
+}
+
+function printDryRun(message) {
+  printInfo(formatDetail('dry-run', message));
+}
+
+function previewStandalone(artifactPath, destPath, markerPath, skipMarkerPath, deployOptions) {
+  const inFlight = findInFlightMarkers(path.dirname(destPath));
+  if (inFlight.length > 0) {
+    printWarning(`in-flight deployment markers present: ${inFlight.join(', ')}`);
+  }
+
+  if (deployOptions.redeploy && fs.existsSync(`${destPath}.deployed`)) {
+    printDryRun(`remove ${destPath}.deployed and wait for .undeployed`);
+  }
+
+  if (skipMarkerPath) {
+    printDryRun(`create ${skipMarkerPath}`);
+  }
+  printDryRun(`copy ${artifactPath} -> ${destPath}`);
+  if (skipMarkerPath) {
+    printDryRun(`remove ${skipMarkerPath}`);
+  }
+  printDryRun(`create ${markerPath}`);
+}
+
+async function undeployStandalone(deployedPath, timeout, result) {
+  const deployedMarker = `${deployedPath}.deployed`;
+  const undeployedMarker = `${deployedPath}.undeployed`;
+
+  if (!fs.existsSync(deployedMarker)) {
+    return;
+  }
+
+  printInfo(formatDetail('redeploy', `undeploying ${path.basename(deployedPath)}`));
+  fs.rmSync(undeployedMarker, { force: true });
+  fs.rmSync(deployedMarker, { force: true });
+  trackMarkerRemoved(result, deployedMarker);
+
+  await waitForUndeployedMarker(deployedPath, timeout, 'deploy');
+
+  fs.rmSync(undeployedMarker, { force: true });
+  trackMarkerRemoved(result, undeployedMarker);
+}
+
+async function waitForUndeployedMarker(deployedPath, timeout, phase) {
+  const undeployedMarker = `${deployedPath}.undeployed`;
+  const deadline = Date.now() + timeout;
+
+  while (!fs.existsSync(undeployedMarker)) {
+    printDebug(`poll ${path.basename(undeployedMarker)}: not yet written`);
+    if (Date.now() >= deadline) {
+      throw new JmwError(`${path.basename(deployedPath)} was not undeployed within ${ms(timeout)}`, {
+        code: 'UNDEPLOY_TIMEOUT',
+        phase,
+        artifact: path.basename(deployedPath)
+      });
+    }
+
+    await new Promise((resolve) => setTimeout(resolve, IN_FLIGHT_POLL_INTERVAL));
+  }
+}
+
+const IN_FLIGHT_MARKERS = ['.isdeploying', '.isundeploying', '.pending'];
+const IN_FLIGHT_POLL_INTERVAL = 500;
+
+// Artifacts of the same --parallel batch are deployed alongside this one,
+// so their markers do not count as another deployment in flight.
+function findInFlightMarkers(deploymentsDir, concurrentArtifacts = []) {
+  if (!fs.existsSync(deploymentsDir)) {
+    return [];
+  }
+
+  return fs.readdirSync(deploymentsDir)
+    .filter((entry) => IN_FLIGHT_MARKERS.some((marker) => entry.endsWith(marker)
+      && !concurrentArtifacts.includes(entry.slice(0, -marker.length))));
+}
+
+async function waitForInFlightMarkers(deploymentsDir, timeout, concurrentArtifacts = []) {
+  const deadline = Date.now() + timeout;
+  let markers = findInFlightMarkers(deploymentsDir, concurrentArtifacts);
+
+  if (markers.length > 0) {
+    printWarning(`waiting for in-flight deployment markers: ${markers.join(', ')}`);
+  }
+
+  while (markers.length > 0) {
+    if (Date.now() >= deadline) {
+      throw new JmwError(
+        `Deployment scanner still busy after ${ms(timeout)} (${markers.join(', ')}). ` +
+        'Wait for the running deployment to finish or remove stale markers from the deployments directory.',
+        { code: 'DEPLOYMENT_IN_FLIGHT', phase: 'deploy' }
+      );
+    }
+
+    await new Promise((resolve) => setTimeout(resolve, IN_FLIGHT_POLL_INTERVAL));
+    markers = findInFlightMarkers(deploymentsDir, concurrentArtifacts);
+    printDebug(`poll in-flight markers: ${markers.length > 0 ? markers.join(', ') : 'none'}`);
+  }
+}
+
+function isNotDeployedOutput(output) {
+  return /WFLYCTL0216|not found|does not exist|doesn't exist/i.test(output);
+}
+
+function lastLine(output) {
+  return output.trim().split('\n').filter(Boolean).pop() || '';
+}
+
+// Streams jboss-cli output as an inherited stdio would while keeping a copy,
+// so a failure can quote the diagnostic even when stdout is redirected.
+function runCliCapturing({ command, args }, timeout) {
+  const { stdout, stderr } = getCommandStreams();
+
+  return new Promise((resolve, reject) => {
+    const child = spawn(command, args, { stdio: ['inherit', 'pipe', 'pipe'] });
+    let output = '';
+    // Reject right away: a killed wrapper script can leave the JVM holding
+    // the pipes open, and 'close' would wait for it.
+    const timer = setTimeout(() => {
+      child.kill('SIGKILL');
+      reject(Object.assign(new Error('jboss-cli timed out'), { output, timedOut: true }));
+    }, timeout);
+
+    child.stdout.on('data', (chunk) => {
+      output += chunk;
+      stdout.write(chunk);
+    });
+    child.stderr.on('data', (chunk) => {
+      output += chunk;
+      stderr.write(chunk);
+    });
+    child.on('error', (error) => {
+      clearTimeout(timer);
+      reject(error);
+    });
+    child.on('close', (code, signal) => {
+      clearTimeout(timer);
+      if (code === 0) {
+        resolve(output);
+        return;
+      }
+
+      const error = new Error(signal ? `jboss-cli killed by ${signal}` : `jboss-cli exited with code ${code}`);
+      error.output = output;
+      reject(error);
+    });
+  });
+}
+
+const FAILURE_DESCRIPTION_PATTERN = /"failure-description"\s*=>\s*/;
+const CLI_FAILURE_LINES = 5;
+
+// Prefers the management model's failure-description over the raw tail.
+function summarizeCliFailure(output) {
+  const match = FAILURE_DESCRIPTION_PATTERN.exec(output);
+  const description = match ? readDmrValue(output.slice(match.index + match[0].length)) : '';
+  if (description) {
+    return description.replace(/^"|"$/g, '').replace(/\s+/g, ' ').trim();
+  }
+
+  return output.trim().split('\n').filter(Boolean).slice(-CLI_FAILURE_LINES).join('\n');
+}
+
+// Reads one quoted string or balanced {...} value from jboss-cli DMR output.
+function readDmrValue(text) {
+  if (text.startsWith('"')) {
+    return /^"(?:[^"\\]|\\.)*"/.exec(text)?.[0] || '';
+  }
+
+  let depth = 0;
+  let quoted = false;
+  for (let index = 0; index < text.length; index++) {
+    const char = text[index];
+    if (char === '\\' && quoted) {
+      index++;
+    } else if (char === '"') {
+      quoted = !quoted;
+    } else if (!quoted && char === '{') {
+      depth++;
+    } else if (!quoted && char === '}' && --depth === 0) {
+      return text.slice(0, index + 1);
+    }
+  }
+
+  return '';
+}
+
+function showCurrentDeployments(cliPath, wildflyConfig) {
+  for (const serverGroup of wildflyConfig.serverGroups) {
+    showGroupDeployments(cliPath, wildflyConfig, serverGroup);
+  }
+}
+
+function showGroupDeployments(cliPath, wildflyConfig, serverGroup) {
+  printInfo(formatDetail('current deployments', serverGroup));
+
+  try {
+    const info = getCliInvocation(cliPath, [...getConnectArgs(wildflyConfig), `--commands=deployment-info --server-group=${serverGroup}`]);
+    debugCliInvocation(info, wildflyConfig);
+    execFileSync(info.command, info.args, {
+      stdio: getCommandStdio(),
+      timeout: wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+  } catch (error) {
+    printWarning(`could not read deployments for ${serverGroup}: ${redactCredentials(error.message, wildflyConfig)}`);
   }
 }
 
@@ -163,5 +838,10 @@ export {
   deployGlobalModule,
   deployNormal,
   deployStandalone,
-  deployDomain
+  deployDomain,
+  undeployStandaloneArtifact,
+  undeployDomainArtifact,
+  splitArtifactName,
+  findStaleDeployments,
+  confirmStaleUndeploys
 };
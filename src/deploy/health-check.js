@@ -0,0 +1,76 @@
+import { parseDuration } from './wildfly.js';
+
+const DEFAULT_HEALTH_CHECK_STATUS = 200;
+const DEFAULT_HEALTH_CHECK_TIMEOUT = '5s';
+const DEFAULT_HEALTH_CHECK_RETRIES = 10;
+const HEALTH_CHECK_INTERVAL = 2000;
+
+// `health_check` is either a URL or { url, expected_status, timeout, retries };
+// `retries` counts the attempts after the first one.
+function parseHealthCheck(value) {
+  if (value === undefined || value === null) {
+    return null;
+  }
+
+  const config = typeof value === 'string' ? { url: value } : value;
+  if (typeof config.url !== 'string' || config.url.length === 0) {
+    throw new Error('health_check.url must be set');
+  }
+
+  const expectedStatus = Number(config.expected_status ?? DEFAULT_HEALTH_CHECK_STATUS);
+  if (!Number.isInteger(expectedStatus) || expectedStatus < 100 || expectedStatus > 599) {
+    throw new Error(`Invalid health_check.expected_status: ${config.expected_status}`);
+  }
+
+  const retries = Number(config.retries ?? DEFAULT_HEALTH_CHECK_RETRIES);
+  if (!Number.isInteger(retries) || retries < 0) {
+    throw new Error(`Invalid health_check.retries: ${config.retries}`);
+  }
+
+  return {
+    url: config.url,
+    expectedStatus,
+    timeout: parseDuration(config.timeout ?? DEFAULT_HEALTH_CHECK_TIMEOUT),
+    retries
+  };
+}
+
+async function runHealthCheck(healthCheck, options = {}) {
+  const fetchImpl = options.fetch || fetch;
+  const interval = options.interval ?? HEALTH_CHECK_INTERVAL;
+  const result = {
+    url: healthCheck.url,
+    expectedStatus: healthCheck.expectedStatus,
+    healthy: false,
+    attempts: 0
+  };
+
+  while (result.attempts <= healthCheck.retries) {
+    if (result.attempts > 0) {
+      await new Promise((resolve) => setTimeout(resolve, interval));
+    }
+    result.attempts += 1;
+
+    try {
+      const response = await fetchImpl(healthCheck.url, { signal: AbortSignal.timeout(healthCheck.timeout) });
+      await response.arrayBuffer();
+      result.status = response.status;
+      delete result.error;
+    } catch (error) {
+      delete result.status;
+      result.error = error.message;
+    }
+
+    if (result.status === healthCheck.expectedStatus) {
+      result.healthy = true;
+      break;
+    }
+  }
+
+  return result;
+}
+
+export {
+  parseHealthCheck,
+  runHealthCheck
+};
@@ -0,0 +1,80 @@
+import path from 'node:path';
+import { execFileSync } from 'node:child_process';
+import { JmwError } from '../errors.js';
+import { formatDetail, getCommandStdio, printCommand, printInfo, printWarning } from '../output.js';
+import { getLocalTargetPaths } from './wildfly.js';
+
+const HOOK_STAGES = Object.freeze({
+  PRE_DEPLOY: 'pre_deploy',
+  POST_DEPLOY: 'post_deploy'
+});
+
+// Each hook setting is one shell command or a list of them.
+function getDeployHooks(projectConfig, stage) {
+  const hooks = projectConfig[stage];
+
+  if (hooks === undefined || hooks === null) {
+    return [];
+  }
+
+  const commands = Array.isArray(hooks) ? hooks : [hooks];
+  if (commands.some((command) => typeof command !== 'string' || command.length === 0)) {
+    throw new Error(`${stage} must be a shell command or a list of shell commands`);
+  }
+
+  return commands;
+}
+
+// JMW_TARGET holds the deployment paths (joined with ':'), or the server
+// groups in domain mode where jboss-cli does the copying.
+function createHookEnv(plan) {
+  const targetPaths = getLocalTargetPaths(plan.artifactPath, plan.wildflyConfig, plan.module, plan.deployOptions);
+
+  return {
+    JMW_ARTIFACT: path.resolve(plan.artifactPath),
+    JMW_PROJECT: plan.project,
+    JMW_MODULE: plan.module.artifactId,
+    JMW_MODE: plan.wildflyConfig.mode,
+    JMW_TARGET: targetPaths.length > 0 ? targetPaths.join(':') : plan.wildflyConfig.serverGroups.join(',')
+  };
+}
+
+// A failing pre_deploy hook aborts the deploy; a failing post_deploy hook only
+// warns, since the artifact is already deployed by then.
+function runDeployHooks(stage, commands, plan, options = {}) {
+  const run = options.run || execFileSync;
+  const env = { ...process.env, ...createHookEnv(plan) };
+
+  for (const command of commands) {
+    if (options.dryRun) {
+      printInfo(formatDetail('dry-run', `run ${stage} hook: ${command}`));
+      continue;
+    }
+
+    printInfo(`${stage} hook`);
+    printCommand(command);
+
+    try {
+      run('sh', ['-c', command], { stdio: getCommandStdio(), env });
+    } catch (error) {
+      const reason = error.status === undefined || error.status === null ? error.message : `exit code ${error.status}`;
+
+      if (stage === HOOK_STAGES.PRE_DEPLOY) {
+        throw new JmwError(`${stage} hook failed (${reason}): ${command}`, {
+          code: 'HOOK_FAILED',
+          phase: 'deploy',
+          artifact: path.basename(plan.artifactPath)
+        });
+      }
+
+      printWarning(`${stage} hook failed (${reason}): ${command}`);
+    }
+  }
+}
+
+export {
+  HOOK_STAGES,
+  getDeployHooks,
+  createHookEnv,
+  runDeployHooks
+};
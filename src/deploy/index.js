@@ -1,37 +1,649 @@
+import fs from 'node:fs';
+import path from 'node:path';
 import { confirm } from '../utils.js';
+import { JmwError } from '../errors.js';
+import { appendAuditEntry } from '../audit.js';
 import { printWarning } from '../output.js';
-import { createDeploymentPlan, getWildflyConfig } from './wildfly.js';
-import { createRemoteDeploymentPlan } from './remote.js';
-import { executeDeploymentPlan } from './execution.js';
+import {
+  assertWildflyRoot,
+  createDeploymentPlan,
+  getAccessUrl,
+  getDeploymentsDir,
+  getLocalServerLogPaths,
+  getLocalTargetPaths,
+  getWildflyConfig,
+  parseDuration,
+  resolveManagementCredentials
+} from './wildfly.js';
+import {
+  createRemoteChecks,
+  createRemoteDeploymentPlan,
+  createRemoteRestartStep,
+  createRemoteSteps,
+  executeRemoteSteps,
+  getRemoteTargetPaths,
+  runRemoteChecks,
+  withRemoteRunner
+} from './remote.js';
+import {
+  showDeploymentSummary,
+  showRemoteCheckResults,
+  showRollbackPlan,
+  showRollbackSuccess,
+  showUndeployPlan,
+  showUndeploySuccess
+} from './reporting.js';
+import { findLatestBackup } from './backup.js';
+import {
+  confirmStaleUndeploys,
+  createDeploymentResult,
+  executeDeploymentPlan,
+  findStaleDeployments,
+  undeployDomainArtifact,
+  undeployStandaloneArtifact
+} from './execution.js';
+import { resolveRestartAction, restartWildfly } from './restart.js';
+import { runWarmup } from './warmup.js';
+import { parseHealthCheck, runHealthCheck } from './health-check.js';
+import { HOOK_STAGES, getDeployHooks, runDeployHooks } from './hooks.js';
+import { parseNotification, sendDeployNotification } from './notify.js';
+import { readGitInfo } from '../project/git.js';
+import { findNewerSources, parseStaleCheck } from '../build/artifacts.js';
+import { checkWildflyMode, checkWildflyRunning } from './server-state.js';
+import { followLogWatch, startLogWatch } from './log-watch.js';
+import { evaluateRestartDecision, overrideRestartDecision, parseRestartSeverity } from '../build/restart.js';
 import { createLifecycle, LIFECYCLE_STAGES } from '../lifecycle/index.js';
 import { createDeployLifecycleHandlers } from '../lifecycle/console-handlers.js';
 
 async function deployArtifact(artifactPath, detection, options = {}) {
-  const plan = createDeploymentPlan(artifactPath, detection);
+  assertArtifactName(artifactPath, detection.projectConfig, options.force);
+  assertRestartOptions(options);
+  if (options.restartSeverity) {
+    parseRestartSeverity(options.restartSeverity);
+  }
+
+  if (options.alsoGlobal && !detection.module.isGlobalModule) {
+    throw new Error(`--also-global requires a global_modules entry for ${detection.module.artifactId}`);
+  }
+
+  const watchTimeout = options.watchTimeout === undefined ? undefined : parseDuration(options.watchTimeout);
+  const healthCheck = parseHealthCheck(detection.projectConfig.health_check);
+  const preDeployHooks = getDeployHooks(detection.projectConfig, HOOK_STAGES.PRE_DEPLOY);
+  const postDeployHooks = getDeployHooks(detection.projectConfig, HOOK_STAGES.POST_DEPLOY);
+  const notification = parseNotification(detection.projectConfig.notification);
+  const plan = createDeploymentPlan(artifactPath, detection, {
+    alsoGlobal: options.alsoGlobal,
+    preUndeployVerify: options.preUndeployVerify,
+    step: options.step,
+    redeploy: options.redeploy,
+    dryRun: options.dryRun,
+    timeout: options.timeout === undefined ? undefined : parseDuration(options.timeout),
+    retryDeploy: parseRetryCount(options.retryDeploy),
+    retryDelay: parseDuration(options.retryDelay ?? DEFAULT_RETRY_DELAY),
+    skipDeployMarker: detection.projectConfig.skip_deploy_marker === true,
+    controller: options.controller,
+    allRelevantServerGroups: options.allRelevantServerGroups,
+    concurrentArtifacts: options.concurrentArtifacts,
+    preflighted: options.preflighted,
+    staleUndeploys: options.staleUndeploys
+  });
+  assertWildflyRoot(plan.wildflyConfig);
+  plan.git = await readGitInfo(detection.module.path);
   const lifecycle = options.lifecycle || createLifecycle(createDeployLifecycleHandlers());
 
+  if (options.onEvent) {
+    lifecycle.addHandlers(createEventHandler(options.onEvent));
+  }
+
   await lifecycle.emit(LIFECYCLE_STAGES.PRE_DEPLOY, {
     detection,
     plan,
     target: createDeployTarget(detection)
   });
 
-  const confirmed = await confirm('jmw: deploy artifact to WildFly?');
+  const modeWarning = checkWildflyMode(plan.wildflyConfig);
+  if (modeWarning) {
+    printWarning(modeWarning);
+  }
+
+  const restartDecision = overrideRestartDecision(await evaluateRestartDecision(detection.module, detection.restartRules, {
+    ...options.restartOptions,
+    artifactName: path.basename(artifactPath),
+    restartOverrides: detection.projectConfig.restart_overrides
+  }), options.restartSeverity);
+
+  if (!options.preflighted && !await confirmFreshArtifact(artifactPath, detection, options)) {
+    printWarning('deployment cancelled');
+    return null;
+  }
+
+  const confirmed = options.dryRun || options.confirmed || await confirm(`jmw: deploy artifact to WildFly? This deploy will: ${formatRestartImpact(restartDecision)}`);
   if (!confirmed) {
     printWarning('deployment cancelled');
     return null;
   }
 
-  const result = executeDeploymentPlan(plan, options.result);
+  if (plan.wildflyConfig.mode === 'domain' && (!options.dryRun || options.allRelevantServerGroups)) {
+    await resolveManagementCredentials(plan.wildflyConfig);
+  }
+
+  // Global modules are picked up on restart, so they can go to a stopped server.
+  if (!options.dryRun && !options.preflighted && !plan.module.isGlobalModule && plan.wildflyConfig.checkRunning && !await confirmWildflyRunning(plan.wildflyConfig)) {
+    printWarning('deployment cancelled');
+    return null;
+  }
+
+  runDeployHooks(HOOK_STAGES.PRE_DEPLOY, preDeployHooks, plan, { dryRun: options.dryRun, run: options.runHook });
+
+  if (options.watch && !options.dryRun && plan.module.isGlobalModule) {
+    printWarning('--watch skipped: global modules are loaded when WildFly restarts');
+  }
+
+  const logWatch = options.watch && !options.dryRun && !plan.module.isGlobalModule
+    ? startLogWatch(getLocalServerLogPaths(plan.wildflyConfig))
+    : null;
+  const result = options.result || createDeploymentResult();
+  try {
+    await executeDeploymentPlan(plan, result, (stage, context) => lifecycle.emit(stage, {
+      detection,
+      plan,
+      ...context,
+      target: createDeployTarget(detection)
+    }));
+  } catch (error) {
+    error.report = createDeploymentReport(plan, result, restartDecision, false);
+    if (!options.dryRun) {
+      recordAudit(detection, artifactPath, {
+        outcome: 'failed',
+        error: error.message,
+        attempts: result.attempts,
+        checksum: getCopiedChecksum(result),
+        restartSeverity: restartDecision.status,
+        note: options.note
+      });
+      await notifyDeploy(notification, detection, artifactPath, 'local WildFly', { succeeded: false, error: error.message, git: plan.git }, options);
+    }
+    throw error;
+  }
+  result.note = options.note;
+  result.restartDecision = restartDecision;
+  result.report = createDeploymentReport(plan, result, restartDecision, true);
+  if (!options.dryRun) {
+    recordAudit(detection, artifactPath, {
+      outcome: 'deployed',
+      attempts: result.attempts,
+      checksum: getCopiedChecksum(result),
+      restartSeverity: restartDecision.status,
+      note: options.note
+    });
+    await notifyDeploy(notification, detection, artifactPath, 'local WildFly', { succeeded: true, git: plan.git }, options);
+  }
+
   await lifecycle.emit(LIFECYCLE_STAGES.POST_DEPLOY, {
     detection,
     plan,
     result,
+    accessUrl: getAccessUrl(artifactPath, detection.projectConfig),
+    restartDecision,
+    restartAdvice: options.restart !== false,
+    target: createDeployTarget(detection)
+  });
+
+  runDeployHooks(HOOK_STAGES.POST_DEPLOY, postDeployHooks, plan, { dryRun: options.dryRun, run: options.runHook });
+
+  if (options.dryRun) {
+    return result;
+  }
+
+  if (logWatch) {
+    await followLogWatch(logWatch, path.basename(artifactPath), {
+      timeout: watchTimeout ?? plan.wildflyConfig.watchTimeout,
+      logPatterns: plan.wildflyConfig.logPatterns
+    });
+  }
+
+  // Global modules only load on restart, so there is nothing to poll yet.
+  if (healthCheck && !plan.module.isGlobalModule) {
+    result.healthCheck = await runHealthCheck(healthCheck, { fetch: options.fetch });
+    result.report.healthCheck = result.healthCheck;
+    await lifecycle.emit(LIFECYCLE_STAGES.HEALTH_CHECK_COMPLETED, {
+      detection,
+      plan,
+      healthCheck: result.healthCheck,
+      target: createDeployTarget(detection)
+    });
+
+    if (!result.healthCheck.healthy && options.failOnUnhealthy) {
+      const error = new JmwError(`Health check of ${healthCheck.url} did not return HTTP ${healthCheck.expectedStatus} after ${result.healthCheck.attempts} attempts`, {
+        code: 'HEALTH_CHECK_FAILED',
+        phase: 'deploy',
+        artifact: path.basename(artifactPath)
+      });
+      error.report = result.report;
+      throw error;
+    }
+  }
+
+  // Warming an application that failed its health check only adds noise.
+  const warmupUrls = detection.projectConfig.warmup_urls || [];
+  const healthy = !result.healthCheck || result.healthCheck.healthy;
+  if (warmupUrls.length > 0 && healthy && !plan.module.isGlobalModule) {
+    const warmup = await runWarmup(warmupUrls, { fetch: options.fetch });
+    await lifecycle.emit(LIFECYCLE_STAGES.WARMUP_COMPLETED, {
+      detection,
+      plan,
+      warmup,
+      target: createDeployTarget(detection)
+    });
+  }
+
+  const restartAction = resolveRestartAction(restartDecision, options);
+  await lifecycle.emit(LIFECYCLE_STAGES.RESTART_DECIDED, {
+    detection,
+    plan,
+    restartDecision,
+    restartAction,
+    restartRequested: Boolean(options.restart || options.forceRestart),
     target: createDeployTarget(detection)
   });
+
+  if (restartAction.restart) {
+    if (!plan.wildflyConfig.restartCmd) {
+      await resolveManagementCredentials(plan.wildflyConfig);
+    }
+    restartWildfly(plan.wildflyConfig, options.runRestart);
+  }
+
+  return result;
+}
+
+async function deployArtifactRemote(artifactPath, detection, clientSelection, options = {}) {
+  assertArtifactName(artifactPath, detection.projectConfig, options.force);
+  assertRestartOptions(options);
+  if (options.restartSeverity) {
+    parseRestartSeverity(options.restartSeverity);
+  }
+
+  const plan = createDeploymentPlan(artifactPath, detection);
+  plan.git = await readGitInfo(detection.module.path);
+  const notification = parseNotification(detection.projectConfig.notification);
+  const lifecycle = options.lifecycle || createLifecycle(createDeployLifecycleHandlers());
+  const target = {
+    ...createDeployTarget(detection),
+    clientName: clientSelection.clientName
+  };
+
+  const steps = createRemoteSteps(artifactPath, plan.wildflyConfig, clientSelection.clientConfig, plan.module);
+
+  let restartDecision = null;
+  if ((options.restart || options.forceRestart) && !plan.module.isGlobalModule) {
+    restartDecision = overrideRestartDecision(await evaluateRestartDecision(detection.module, detection.restartRules, {
+      ...options.restartOptions,
+      artifactName: path.basename(artifactPath),
+      restartOverrides: detection.projectConfig.restart_overrides
+    }), options.restartSeverity);
+
+    if (resolveRestartAction(restartDecision, options).restart) {
+      steps.push(createRemoteRestartStep(clientSelection.clientConfig));
+    }
+  }
+
+  await lifecycle.emit(LIFECYCLE_STAGES.PRE_REMOTE_DEPLOY, { detection, plan, steps, target });
+
+  if (!await confirmFreshArtifact(artifactPath, detection, options)) {
+    printWarning('remote deployment cancelled');
+    return null;
+  }
+
+  if (options.dryRun) {
+    const checks = createRemoteChecks(plan.wildflyConfig, clientSelection.clientConfig, plan.module);
+    const results = await withRemoteRunner(clientSelection.clientConfig, options.runCommand, (run) => runRemoteChecks(checks, run));
+    showRemoteCheckResults(results);
+
+    if (results.some((check) => !check.passed)) {
+      throw new Error(`Remote checks for ${clientSelection.clientName} failed`);
+    }
+
+    return null;
+  }
+
+  const confirmed = options.confirmed || await confirm(`jmw: deploy artifact to ${clientSelection.clientName}?`);
+  if (!confirmed) {
+    printWarning('remote deployment cancelled');
+    return null;
+  }
+
+  const execution = await withRemoteRunner(clientSelection.clientConfig, options.runCommand, (run) => executeRemoteSteps(steps, run));
+  execution.note = options.note;
+  execution.restartDecision = restartDecision;
+  execution.report = createRemoteDeploymentReport(plan, clientSelection, execution);
+  recordAudit(detection, artifactPath, {
+    outcome: execution.succeeded ? 'deployed' : 'failed',
+    client: clientSelection.clientName,
+    restartSeverity: restartDecision?.status,
+    note: options.note
+  });
+  await notifyDeploy(notification, detection, artifactPath, clientSelection.clientName, { succeeded: execution.succeeded, git: plan.git }, options);
+  await lifecycle.emit(LIFECYCLE_STAGES.POST_REMOTE_DEPLOY, {
+    detection,
+    plan,
+    steps,
+    execution,
+    accessUrl: getAccessUrl(artifactPath, detection.projectConfig, clientSelection.clientConfig.host),
+    target
+  });
+
+  if (!execution.succeeded) {
+    const error = new JmwError(`Remote deployment to ${clientSelection.clientName} failed`, {
+      code: 'REMOTE_DEPLOY_FAILED',
+      phase: 'remote',
+      artifact: path.basename(artifactPath)
+    });
+    error.report = execution.report;
+    throw error;
+  }
+
+  return execution;
+}
+
+async function undeployArtifact(artifactName, detection, options = {}) {
+  if (detection.module.isGlobalModule) {
+    throw new JmwError(`${artifactName} is a global module; remove it from the module directory and restart WildFly instead`, {
+      code: 'UNDEPLOY_UNSUPPORTED',
+      phase: 'undeploy',
+      artifact: artifactName
+    });
+  }
+
+  const wildflyConfig = getWildflyConfig(detection.projectConfig);
+  assertWildflyRoot(wildflyConfig);
+  showUndeployPlan(artifactName, wildflyConfig);
+
+  const confirmed = options.confirmed || await confirm(`jmw: undeploy ${artifactName} from WildFly?`);
+  if (!confirmed) {
+    printWarning('undeploy cancelled');
+    return null;
+  }
+
+  if (wildflyConfig.mode === 'domain') {
+    await resolveManagementCredentials(wildflyConfig);
+  }
+
+  const result = createDeploymentResult();
+  try {
+    if (wildflyConfig.mode === 'standalone') {
+      await undeployStandaloneArtifact(artifactName, wildflyConfig, result);
+    } else {
+      undeployDomainArtifact(artifactName, wildflyConfig, result);
+    }
+  } catch (error) {
+    recordAudit(detection, artifactName, { action: 'undeploy', outcome: 'failed', error: error.message });
+    throw error;
+  }
+
+  recordAudit(detection, artifactName, { action: 'undeploy', outcome: 'undeployed' });
+  showUndeploySuccess(artifactName);
+  showDeploymentSummary(result);
+
   return result;
 }
 
+async function rollbackArtifact(artifactName, detection, options = {}) {
+  const wildflyConfig = getWildflyConfig(detection.projectConfig);
+  assertWildflyRoot(wildflyConfig);
+
+  const targetPaths = getLocalTargetPaths(artifactName, wildflyConfig, detection.module);
+  if (targetPaths.length === 0) {
+    throw new JmwError(`Rollback is not supported in ${wildflyConfig.mode} mode; redeploy the previous artifact instead`, {
+      code: 'ROLLBACK_UNSUPPORTED',
+      phase: 'rollback',
+      artifact: artifactName
+    });
+  }
+
+  const restores = targetPaths.map((targetPath) => {
+    const backupPath = findLatestBackup(targetPath, wildflyConfig.root);
+    if (!backupPath) {
+      throw new JmwError(`No backup found for ${targetPath}`, {
+        code: 'BACKUP_NOT_FOUND',
+        phase: 'rollback',
+        artifact: artifactName
+      });
+    }
+
+    return { targetPath, backupPath };
+  });
+
+  showRollbackPlan(artifactName, restores);
+
+  const confirmed = options.confirmed || await confirm(`jmw: restore ${artifactName} from backup?`);
+  if (!confirmed) {
+    printWarning('rollback cancelled');
+    return null;
+  }
+
+  restores.forEach(({ targetPath, backupPath }) => fs.copyFileSync(backupPath, targetPath));
+
+  // Global modules are only reloaded on restart; deployments are picked up
+  // by the scanner once .dodeploy is back.
+  if (!detection.module.isGlobalModule) {
+    restores.forEach(({ targetPath }) => {
+      fs.rmSync(`${targetPath}.failed`, { force: true });
+      fs.writeFileSync(`${targetPath}.dodeploy`, '');
+    });
+  }
+
+  recordAudit(detection, artifactName, { outcome: 'rolled-back', backups: restores.map((restore) => restore.backupPath) });
+  showRollbackSuccess(artifactName, detection.module.isGlobalModule);
+
+  return restores;
+}
+
+const DEFAULT_RETRY_DELAY = '5s';
+
+function parseRetryCount(value) {
+  if (value === undefined) {
+    return 0;
+  }
+
+  const count = Number(value);
+  if (!Number.isInteger(count) || count < 0) {
+    throw new Error(`Invalid --retry-deploy count '${value}'. Expected a non-negative integer`);
+  }
+
+  return count;
+}
+
+const RESTART_IMPACT_LABELS = {
+  required: 'RESTART REQUIRED',
+  recommended: 'restart recommended',
+  'not-required': 'no restart needed',
+  unknown: 'restart impact unknown'
+};
+
+// Parallel workers buffer their output until they settle, so a prompt inside
+// one would wait on an answer to a question nobody can see. Everything a
+// worker would ask is asked here, once, before the pool starts; deployArtifact
+// then runs with `preflighted` and the collected `staleUndeploys`. Returns the
+// artifacts still to deploy, or null when the whole batch was cancelled.
+async function preflightParallelDeploy(artifactPaths, detection, options = {}) {
+  const wildflyConfig = getWildflyConfig(detection.projectConfig);
+  const ready = [];
+
+  for (const artifactPath of artifactPaths) {
+    if (await confirmFreshArtifact(artifactPath, detection, options)) {
+      ready.push(artifactPath);
+    } else {
+      printWarning(`${path.basename(artifactPath)} skipped`);
+    }
+  }
+
+  if (ready.length === 0 || options.dryRun) {
+    return { artifactPaths: ready, staleUndeploys: {} };
+  }
+
+  if (detection.module.isGlobalModule) {
+    const missing = detection.module.deploymentPaths
+      .map((deploymentPath) => path.join(wildflyConfig.root, deploymentPath))
+      .filter((modulePath) => !fs.existsSync(modulePath));
+    if (missing.length > 0 && !await confirm(`jmw: create module director${missing.length === 1 ? 'y' : 'ies'} ${missing.join(', ')}?`)) {
+      printWarning('deployment cancelled');
+      return null;
+    }
+  } else if (wildflyConfig.checkRunning && !await confirmWildflyRunning(wildflyConfig)) {
+    printWarning('deployment cancelled');
+    return null;
+  }
+
+  // Other versions that are part of this batch are being deployed, not
+  // stale, and each stale version is undeployed by one worker only.
+  const batchNames = ready.map((artifactPath) => path.basename(artifactPath));
+  const claimed = new Set(batchNames);
+  const staleUndeploys = {};
+  for (const artifactName of batchNames) {
+    const staleDeployments = findStaleDeployments(getDeploymentsDir(wildflyConfig), artifactName, detection.module)
+      .filter((staleName) => !claimed.has(staleName));
+    staleDeployments.forEach((staleName) => claimed.add(staleName));
+    staleUndeploys[artifactName] = await confirmStaleUndeploys(staleDeployments, artifactName);
+  }
+
+  return { artifactPaths: ready, staleUndeploys };
+}
+
+async function confirmWildflyRunning(wildflyConfig) {
+  const state = checkWildflyRunning(wildflyConfig);
+  if (state.running !== false) {
+    return true;
+  }
+
+  printWarning(`WildFly at ${wildflyConfig.root} appears to be stopped: ${state.detail}. ${wildflyConfig.mode === 'standalone'
+    ? 'The deployment scanner will not pick up the .dodeploy marker until it starts'
+    : 'jboss-cli deploy will fail to connect'}`);
+  return confirm('jmw: deploy anyway?');
+}
+
+function formatRestartImpact(decision) {
+  return `${RESTART_IMPACT_LABELS[decision.status] || RESTART_IMPACT_LABELS.unknown} (${decision.reason})`;
+}
+
+function assertArtifactName(artifactPath, projectConfig, force = false) {
+  if (!projectConfig.artifact_name_pattern) {
+    return;
+  }
+
+  const artifactName = path.basename(artifactPath);
+  if (new RegExp(projectConfig.artifact_name_pattern).test(artifactName)) {
+    return;
+  }
+
+  const message = `Artifact name '${artifactName}' does not match artifact_name_pattern ${projectConfig.artifact_name_pattern}`;
+  if (!force) {
+    throw new JmwError(`${message}. Use --force to deploy anyway.`, {
+      code: 'ARTIFACT_NAME_INVALID',
+      phase: 'validate',
+      artifact: artifactName
+    });
+  }
+
+  printWarning(`${message} (forced)`);
+}
+
+function assertRestartOptions(options) {
+  if (options.restart === false && options.forceRestart) {
+    throw new Error('--force-restart cannot be combined with --no-restart');
+  }
+}
+
+function createDeploymentReport(plan, result, restartDecision, succeeded) {
+  return {
+    project: plan.project,
+    module: plan.module.artifactId,
+    artifact: path.basename(plan.artifactPath),
+    targetPaths: getLocalTargetPaths(plan.artifactPath, plan.wildflyConfig, plan.module, plan.deployOptions),
+    isGlobal: plan.module.isGlobalModule,
+    restartSeverity: restartDecision.status,
+    restartReason: restartDecision.reason,
+    duration: (result.endTime || new Date()) - result.startTime,
+    succeeded,
+    dryRun: result.dryRun === true,
+    backup: result.backup || null
+  };
+}
+
+function createRemoteDeploymentReport(plan, clientSelection, execution) {
+  return {
+    project: plan.project,
+    module: plan.module.artifactId,
+    artifact: path.basename(plan.artifactPath),
+    client: clientSelection.clientName,
+    targetPaths: getRemoteTargetPaths(plan.artifactPath, plan.wildflyConfig, clientSelection.clientConfig, plan.module),
+    isGlobal: plan.module.isGlobalModule,
+    restartSeverity: execution.restartDecision?.status ?? null,
+    restartReason: execution.restartDecision?.reason ?? null,
+    succeeded: execution.succeeded
+  };
+}
+
+// Deploying a JAR that predates the sources is usually a forgotten rebuild, so
+// it needs its own confirmation even in a batch; --dry-run only warns.
+async function confirmFreshArtifact(artifactPath, detection, options) {
+  const staleCheck = parseStaleCheck(detection.projectConfig.stale_check);
+  if (!staleCheck || !detection.module.path) {
+    return true;
+  }
+
+  const newerSources = findNewerSources(detection.module, artifactPath, staleCheck);
+  if (newerSources.length === 0) {
+    return true;
+  }
+
+  const artifactName = path.basename(artifactPath);
+  printWarning(`artifact may be stale — rebuild? ${newerSources.length} file${newerSources.length === 1 ? '' : 's'} changed after ${artifactName} was built, newest ${newerSources[0].file}`);
+
+  return options.dryRun || confirm(`jmw: deploy ${artifactName} anyway?`);
+}
+
+// `notify: false` comes from --no-notify.
+async function notifyDeploy(notification, detection, artifactPath, target, outcome, options) {
+  if (!notification || options.notify === false) {
+    return;
+  }
+
+  await sendDeployNotification(notification, {
+    project: detection.project,
+    module: detection.module.artifactId,
+    artifact: path.basename(artifactPath),
+    directory: detection.module.path,
+    target,
+    ...outcome
+  }, { fetch: options.fetch });
+}
+
+function getCopiedChecksum(result) {
+  return result.actions.find((action) => action.type === 'file_copied' && action.checksum)?.checksum;
+}
+
+function recordAudit(detection, artifactPath, fields) {
+  try {
+    appendAuditEntry(detection.auditLog, {
+      project: detection.project,
+      module: detection.module?.artifactId,
+      artifact: path.basename(artifactPath),
+      mode: detection.projectConfig.wildfly_mode || 'standalone',
+      ...fields
+    });
+  } catch (error) {
+    printWarning(`could not write audit log: ${error.message}`);
+  }
+}
+
+function createEventHandler(onEvent) {
+  return {
+    stage: Object.values(LIFECYCLE_STAGES),
+    run: (context) => onEvent(context)
+  };
+}
+
 function createDeployTarget(detection) {
   return {
     project: detection.project,
@@ -43,6 +655,12 @@ function createDeployTarget(detection) {
 
 export {
   deployArtifact,
+  deployArtifactRemote,
+  preflightParallelDeploy,
+  rollbackArtifact,
+  undeployArtifact,
+  createDeploymentReport,
+  createRemoteDeploymentReport,
   getWildflyConfig,
   createDeploymentPlan,
   createRemoteDeploymentPlan,
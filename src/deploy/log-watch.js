@@ -0,0 +1,192 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import { spawn } from 'node:child_process';
+import ms from 'ms';
+import { classifyLogLine } from './server-log.js';
+import { createRemoteLogCommand } from './remote.js';
+import {
+  formatCommand,
+  formatDetail,
+  printDebug,
+  printInfo,
+  printSection,
+  printSuccess,
+  printWarning
+} from '../output.js';
+
+const WATCH_POLL_INTERVAL = 500;
+const TAIL_CHUNK_SIZE = 64 * 1024;
+const RELEVANT_LINE = /Deployed|WFLYSRV|\b(ERROR|WARN)\b/;
+
+// Taken before the deploy, so lines WildFly writes while jmw waits for the
+// deployment marker are not missed.
+function startLogWatch(logPaths) {
+  return logPaths.map((logPath) => ({
+    logPath,
+    offset: fs.existsSync(logPath) ? fs.statSync(logPath).size : 0,
+    pending: ''
+  }));
+}
+
+// Prints the deploy-related lines written since startLogWatch until a
+// success or failure line names the artifact, or the timeout elapses.
+async function followLogWatch(watch, artifactName, { timeout, logPatterns }) {
+  printSection('watch', [
+    formatDetail('log', watch.map((entry) => entry.logPath).join(', ')),
+    formatDetail('timeout', ms(timeout))
+  ]);
+
+  const deadline = Date.now() + timeout;
+  for (;;) {
+    for (const entry of watch) {
+      for (const line of readNewLines(entry)) {
+        if (!line.includes(artifactName) && !RELEVANT_LINE.test(line)) {
+          continue;
+        }
+
+        printInfo(`  ${line}`);
+        const outcome = line.includes(artifactName) ? classifyLogLine(line, logPatterns) : null;
+
+        if (outcome === 'success') {
+          printSuccess(`server.log reports ${artifactName} deployed`);
+          return 'success';
+        }
+
+        if (outcome === 'failure') {
+          printWarning(`server.log reports a failure for ${artifactName}`);
+          return 'failure';
+        }
+      }
+    }
+
+    if (Date.now() >= deadline) {
+      printWarning(`no success or failure line for ${artifactName} in server.log within ${ms(timeout)}`);
+      return 'timeout';
+    }
+
+    await new Promise((resolve) => setTimeout(resolve, WATCH_POLL_INTERVAL));
+  }
+}
+
+// Like `tail -n <lines> -f | grep`: the last lines of each log, then every
+// line appended to it, until the process is interrupted. Lines of several
+// domain servers are prefixed with the server name.
+async function followServerLogs(logPaths, { lines, pattern, onLine }) {
+  const watch = startLogWatch(logPaths);
+  const emit = (entry, line) => {
+    if (!pattern || pattern.test(line)) {
+      onLine(watch.length > 1 ? `[${path.basename(path.dirname(path.dirname(entry.logPath)))}] ${line}` : line);
+    }
+  };
+
+  printSection('logs', [formatDetail('log', logPaths.join(', '))]);
+  for (const entry of watch) {
+    if (!fs.existsSync(entry.logPath)) {
+      printWarning(`${entry.logPath} does not exist yet, waiting for it`);
+      continue;
+    }
+
+    readLastLines(entry.logPath, lines).forEach((line) => emit(entry, line));
+  }
+
+  for (;;) {
+    for (const entry of watch) {
+      readNewLines(entry).forEach((line) => emit(entry, line));
+    }
+
+    await new Promise((resolve) => setTimeout(resolve, WATCH_POLL_INTERVAL));
+  }
+}
+
+// `tail -f` runs on the client; lines are filtered here so --grep means the
+// same regular expression locally and remotely.
+function followRemoteLog(wildflyConfig, clientConfig, { lines, pattern, onLine }) {
+  const { command, args } = createRemoteLogCommand(wildflyConfig, clientConfig, lines);
+  printSection('logs', [formatDetail('client', clientConfig.host)], { client: clientConfig.host });
+  printDebug(`exec ${formatCommand(command, args)}`);
+
+  return new Promise((resolve, reject) => {
+    const child = spawn(command, args, { stdio: ['ignore', 'pipe', 'inherit'] });
+    let pending = '';
+
+    child.stdout.on('data', (chunk) => {
+      const received = `${pending}${chunk.toString('utf8')}`.split('\n');
+      pending = received.pop();
+      received
+        .map((line) => line.replace(/\r$/, ''))
+        .filter((line) => line && (!pattern || pattern.test(line)))
+        .forEach(onLine);
+    });
+    child.on('error', reject);
+    child.on('close', (code) => (code === 0 ? resolve() : reject(new Error(`${command} exited with code ${code}`))));
+  });
+}
+
+// Reads whole chunks backwards from the end, so a large log is never read
+// in full for a short backfill.
+function readLastLines(logPath, count) {
+  if (count === 0) {
+    return [];
+  }
+
+  const chunks = [];
+  const fd = fs.openSync(logPath, 'r');
+  try {
+    let position = fs.fstatSync(fd).size;
+    let newlines = 0;
+
+    while (position > 0 && newlines <= count) {
+      const length = Math.min(TAIL_CHUNK_SIZE, position);
+      position -= length;
+      const chunk = Buffer.alloc(length);
+      fs.readSync(fd, chunk, 0, length, position);
+      chunks.unshift(chunk);
+      newlines += chunk.toString('latin1').split('\n').length - 1;
+    }
+  } finally {
+    fs.closeSync(fd);
+  }
+
+  return Buffer.concat(chunks).toString('utf8')
+    .split('\n')
+    .map((line) => line.replace(/\r$/, ''))
+    .filter(Boolean)
+    .slice(-count);
+}
+
+function readNewLines(entry) {
+  if (!fs.existsSync(entry.logPath)) {
+    return [];
+  }
+
+  const size = fs.statSync(entry.logPath).size;
+  if (size < entry.offset) {
+    printDebug(`${entry.logPath} was rotated, reading from the start`);
+    entry.offset = 0;
+    entry.pending = '';
+  }
+
+  if (size === entry.offset) {
+    return [];
+  }
+
+  const buffer = Buffer.alloc(size - entry.offset);
+  const fd = fs.openSync(entry.logPath, 'r');
+  try {
+    fs.readSync(fd, buffer, 0, buffer.length, entry.offset);
+  } finally {
+    fs.closeSync(fd);
+  }
+  entry.offset = size;
+
+  const lines = `${entry.pending}${buffer.toString('utf8')}`.split('\n');
+  entry.pending = lines.pop();
+  return lines.map((line) => line.replace(/\r$/, '')).filter(Boolean);
+}
+
+export {
+  startLogWatch,
+  followLogWatch,
+  followServerLogs,
+  followRemoteLog
+};
@@ -0,0 +1,118 @@
+import fs from 'node:fs';
+import path from 'node:path';
+
+const MODULE_XML = 'module.xml';
+const GENERATED_MARKER = '<!-- Generated by jmw from global_modules; changes are overwritten on the next deploy -->';
+
+// modules/com/acme/ejb/main -> com.acme.ejb: the directories below
+// modules/, without the slot.
+function getDefaultModuleName(deploymentPath) {
+  const parts = deploymentPath.split(/[\\/]+/).filter(Boolean);
+  const start = parts[0] === 'modules' ? 1 : 0;
+
+  return parts.slice(start, -1).join('.');
+}
+
+function renderModuleXml({ name, dependencies, resourceRoots }) {
+  const lines = [
+    '<?xml version="1.0" encoding="UTF-8"?>',
+    GENERATED_MARKER,
+    `<module xmlns="urn:jboss:module:1.9" name="${escapeXml(name)}">`,
+    '    <resources>',
+    ...resourceRoots.map((resourceRoot) => `        <resource-root path="${escapeXml(resourceRoot)}"/>`),
+    '    </resources>'
+  ];
+
+  if (dependencies.length > 0) {
+    lines.push(
+      '    <dependencies>',
+      ...dependencies.map((dependency) => `        <module name="${escapeXml(dependency)}"/>`),
+      '    </dependencies>'
+    );
+  }
+
+  return `${[...lines, '</module>'].join('\n')}\n`;
+}
+
+// Returns { status, filePath, name, skipped }: 'up-to-date' when the file
+// already matches, 'hand-written' when an existing module.xml was not
+// generated by jmw (it is never overwritten), otherwise 'written' (or
+// 'would-write'). skipped lists the JARs left out of module.xml.
+function syncModuleXml(modulePath, deploymentPath, moduleXml, { artifactName, dryRun = false } = {}) {
+  const filePath = path.join(modulePath, MODULE_XML);
+  const name = moduleXml.name || getDefaultModuleName(deploymentPath);
+  const jars = fs.existsSync(modulePath)
+    ? fs.readdirSync(modulePath).filter((entry) => entry.toLowerCase().endsWith('.jar'))
+    : [];
+  const resourceRoots = selectResourceRoots(modulePath, jars, artifactName, moduleXml.owners?.[deploymentPath] || []);
+  const skipped = jars.filter((jar) => !resourceRoots.includes(jar));
+  const content = renderModuleXml({ name, dependencies: moduleXml.dependencies, resourceRoots });
+
+  if (fs.existsSync(filePath)) {
+    const existing = fs.readFileSync(filePath, 'utf8');
+
+    if (existing === content) {
+      return { status: 'up-to-date', filePath, name, skipped };
+    }
+
+    if (!existing.includes(GENERATED_MARKER)) {
+      return { status: 'hand-written', filePath, name, skipped };
+    }
+  }
+
+  if (dryRun) {
+    return { status: 'would-write', filePath, name, skipped };
+  }
+
+  fs.writeFileSync(filePath, content);
+  return { status: 'written', filePath, name, skipped };
+}
+
+// One resource root per global_modules entry that shares the module: the
+// artifact being deployed for its own entry, the newest JAR for the others.
+// Older versions (core-1.0.jar next to core-1.1.jar) and JARs no entry owns
+// would load duplicate or unknown classes, so they are not listed.
+function selectResourceRoots(modulePath, jars, artifactName, owners) {
+  const artifactOwner = findJarOwner(artifactName, owners);
+  const newest = new Map();
+
+  for (const jar of jars) {
+    const owner = findJarOwner(jar, owners);
+    if (!owner || owner === artifactOwner) {
+      continue;
+    }
+
+    const mtime = fs.statSync(path.join(modulePath, jar)).mtimeMs;
+    if (!newest.has(owner) || mtime > newest.get(owner).mtime) {
+      newest.set(owner, { jar, mtime });
+    }
+  }
+
+  return [artifactName, ...[...newest.values()].map(({ jar }) => jar)].filter(Boolean).sort();
+}
+
+// core-1.1.jar and core.jar belong to 'core'; with both 'core' and
+// 'core-api' configured, core-api-2.0.jar belongs to the longer key.
+function findJarOwner(jar, owners) {
+  if (!jar) {
+    return null;
+  }
+
+  return owners
+    .filter((owner) => jar === `${owner}.jar` || jar.startsWith(`${owner}-`))
+    .sort((a, b) => b.length - a.length)[0] || null;
+}
+
+function escapeXml(value) {
+  return String(value)
+    .replace(/&/g, '&amp;')
+    .replace(/"/g, '&quot;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;');
+}
+
+export {
+  getDefaultModuleName,
+  renderModuleXml,
+  syncModuleXml
+};
@@ -0,0 +1,82 @@
+import os from 'node:os';
+import { printDebug, printWarning } from '../output.js';
+import { readGitInfo } from '../project/git.js';
+import { parseDuration } from './wildfly.js';
+
+const DEFAULT_NOTIFY_TEMPLATE = '{user} deployed {artifact} ({project}) to {target}: {result}';
+const DEFAULT_NOTIFY_TIMEOUT = '10s';
+
+// `notification` is { webhook_url, template, timeout }; the template's
+// {placeholders} are filled from the deploy and unknown ones are left as is.
+function parseNotification(value) {
+  if (value === undefined || value === null) {
+    return null;
+  }
+
+  if (typeof value.webhook_url !== 'string' || value.webhook_url.length === 0) {
+    throw new Error('notification.webhook_url must be set');
+  }
+
+  if (value.template !== undefined && typeof value.template !== 'string') {
+    throw new Error('notification.template must be a string');
+  }
+
+  return {
+    webhookUrl: value.webhook_url,
+    template: value.template ?? DEFAULT_NOTIFY_TEMPLATE,
+    timeout: parseDuration(value.timeout ?? DEFAULT_NOTIFY_TIMEOUT)
+  };
+}
+
+function renderNotifyTemplate(template, fields) {
+  return template.replace(/\{(\w+)\}/g, (placeholder, name) => (
+    Object.hasOwn(fields, name) ? String(fields[name] ?? '') : placeholder
+  ));
+}
+
+// Posts { text, ...fields } so that a Slack incoming webhook shows `text` and
+// other receivers get the structured fields. Never throws: a lost
+// notification must not turn a deploy into a failure.
+async function sendDeployNotification(notification, deploy, options = {}) {
+  const fetchImpl = options.fetch || fetch;
+  const git = deploy.git !== undefined ? deploy.git : await readGitInfo(deploy.directory, options.gitFactory);
+  const fields = {
+    project: deploy.project,
+    module: deploy.module,
+    artifact: deploy.artifact,
+    target: deploy.target,
+    user: os.userInfo().username,
+    host: os.hostname(),
+    result: deploy.succeeded ? 'succeeded' : 'failed',
+    error: deploy.error ?? null,
+    commit: git?.commit ?? null,
+    branch: git?.branch ?? null
+  };
+
+  try {
+    const response = await fetchImpl(notification.webhookUrl, {
+      method: 'POST',
+      headers: { 'content-type': 'application/json' },
+      body: JSON.stringify({ text: renderNotifyTemplate(notification.template, fields), ...fields }),
+      signal: AbortSignal.timeout(notification.timeout)
+    });
+    await response.arrayBuffer();
+
+    if (!response.ok) {
+      printWarning(`deploy notification failed: HTTP ${response.status}`);
+      return false;
+    }
+  } catch (error) {
+    printWarning(`deploy notification failed: ${error.message}`);
+    return false;
+  }
+
+  printDebug(`deploy notification sent to ${new URL(notification.webhookUrl).host}`);
+  return true;
+}
+
+export {
+  parseNotification,
+  renderNotifyTemplate,
+  sendDeployNotification
+};
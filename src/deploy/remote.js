@@ -1,21 +1,30 @@
 import path from 'node:path';
+import { runCommand } from '../build/maven.js';
+import { formatCommand, getCommandStdio, printCommand, printInfo } from '../output.js';
+import { quoteRemoteCommand, shellQuote } from '../utils.js';
+import { createSftpRunner } from './sftp.js';
+import { assertModulePath, cliQuote, getServerLogPath } from './wildfly.js';
 
 function createRemoteDeploymentPlan(artifactPath, wildflyConfig, clientConfig, moduleInfo, projectName = '') {
   const artifactName = path.basename(artifactPath);
   const artifactExtension = path.extname(artifactName).toLowerCase();
-  const logPath = `${clientConfig.wildfly_path}/${wildflyConfig.mode}/log/server.log`;
+  const logPath = getServerLogPath(clientConfig.wildfly_path, wildflyConfig.mode, clientConfig.log_dir, path.posix.join);
   const sudo = clientConfig.user === 'root' ? '' : 'sudo ';
+  const remoteTempDir = getRemoteTempDir(clientConfig);
   const defaultRemoteCopyDir = wildflyConfig.mode === 'domain'
-    ? '/tmp'
+    ? remoteTempDir
     : `${clientConfig.wildfly_path}/${wildflyConfig.mode}/deployments`;
   const remoteCopyDir = clientConfig.remote_copy_dir || defaultRemoteCopyDir;
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const localArtifact = shellQuote(artifactPath);
+  const tailLogCommand = `ssh ${target} ${quoteRemoteCommand(`${sudo}tail -n 20 -f ${shellQuote(logPath)}`)}`;
 
   if (projectName === 'sinfomar') {
-    if (artifactExtension !== '.war') {
+    if (artifactExtension !== '.war' && artifactExtension !== '.ear') {
       return {
         title: 'remote commands',
         variant: 'sinfomar-non-war',
-        warning: `${artifactName} is not a .war`,
+        warning: `${artifactName} is not a .war or .ear`,
         steps: []
       };
     }
@@ -25,31 +34,31 @@ function createRemoteDeploymentPlan(artifactPath, wildflyConfig, clientConfig, m
       variant: 'sinfomar-war-copy',
       steps: [
         {
-          title: 'Copy WAR to remote target',
-          command: `scp ${artifactPath} ${clientConfig.user}@${clientConfig.host}:${remoteCopyDir}/`
+          title: `Copy ${artifactExtension.slice(1).toUpperCase()} to remote target`,
+          command: `scp ${localArtifact} ${shellQuote(`${target}:${remoteCopyDir}/`)}`
         }
       ]
     };
   }
 
   if (moduleInfo?.isGlobalModule) {
-    const modulesPath = `${clientConfig.wildfly_path}/${moduleInfo.deploymentPath}`;
+    const copySteps = moduleInfo.deploymentPaths.map((deploymentPath) => ({
+      title: 'Copy artifact to WildFly modules',
+      command: `scp ${localArtifact} ${shellQuote(`${target}:${clientConfig.wildfly_path}/${deploymentPath}/`)}`
+    }));
 
     return {
       title: 'remote commands',
       variant: 'global-module',
       steps: [
-        {
-          title: 'Copy artifact to WildFly modules',
-          command: `scp ${artifactPath} ${clientConfig.user}@${clientConfig.host}:${modulesPath}/`
-        },
+        ...copySteps,
         {
           title: 'Restart WildFly (required for global modules)',
-          command: `ssh ${clientConfig.user}@${clientConfig.host} "${clientConfig.restart_cmd}"`
+          command: `ssh ${target} ${quoteRemoteCommand(clientConfig.restart_cmd)}`
         },
         {
           title: 'Watch server logs',
-          command: `ssh ${clientConfig.user}@${clientConfig.host} "${sudo}tail -n 20 -f ${logPath}"`
+          command: tailLogCommand
         }
       ]
     };
@@ -62,15 +71,15 @@ function createRemoteDeploymentPlan(artifactPath, wildflyConfig, clientConfig, m
       steps: [
         {
           title: 'Copy artifact to WildFly host (temporary path)',
-          command: `scp ${artifactPath} ${clientConfig.user}@${clientConfig.host}:/tmp/${artifactName}`
+          command: `scp ${localArtifact} ${shellQuote(`${target}:${remoteTempDir}/${artifactName}`)}`
         },
         {
           title: 'Deploy using jboss-cli (domain mode)',
-          command: `ssh ${clientConfig.user}@${clientConfig.host} "${sudo}${clientConfig.wildfly_path}/bin/jboss-cli.sh --connect --commands='deploy /tmp/${artifactName} --name=${artifactName} --runtime-name=${artifactName} --server-groups=${wildflyConfig.serverGroup} --force'"`
+          command: `ssh ${target} ${quoteRemoteCommand(`${sudo}${shellQuote(`${clientConfig.wildfly_path}/bin/jboss-cli.sh`)} --connect --commands=${shellQuote(`deploy ${cliQuote(`${remoteTempDir}/${artifactName}`)} --name=${cliQuote(artifactName)} --runtime-name=${cliQuote(artifactName)} --server-groups=${wildflyConfig.serverGroup} --force`)}`)}`
         },
         {
           title: 'Watch deployment logs',
-          command: `ssh ${clientConfig.user}@${clientConfig.host} "${sudo}tail -n 20 -f ${logPath}"`
+          command: tailLogCommand
         }
       ]
     };
@@ -84,20 +93,315 @@ function createRemoteDeploymentPlan(artifactPath, wildflyConfig, clientConfig, m
     steps: [
       {
         title: 'Copy artifact to WildFly',
-        command: `scp ${artifactPath} ${clientConfig.user}@${clientConfig.host}:${deploymentsPath}/`
+        command: `scp ${localArtifact} ${shellQuote(`${target}:${deploymentsPath}/`)}`
       },
       {
         title: 'Trigger hot deployment',
-        command: `ssh ${clientConfig.user}@${clientConfig.host} "${sudo}touch ${deploymentsPath}/${artifactName}.dodeploy"`
+        command: `ssh ${target} ${quoteRemoteCommand(`${sudo}touch ${shellQuote(`${deploymentsPath}/${artifactName}.dodeploy`)}`)}`
       },
       {
         title: 'Watch deployment logs',
-        command: `ssh ${clientConfig.user}@${clientConfig.host} "${sudo}tail -n 20 -f ${logPath}"`
+        command: tailLogCommand
       }
     ]
   };
 }
 
+function getRemoteTempDir(clientConfig) {
+  return clientConfig.remote_temp_dir || '/tmp';
+}
+
+function getRemoteTargetPaths(artifactPath, wildflyConfig, clientConfig, moduleInfo) {
+  const artifactName = path.basename(artifactPath);
+
+  if (moduleInfo?.isGlobalModule) {
+    return moduleInfo.deploymentPaths.map((deploymentPath) => `${clientConfig.wildfly_path}/${deploymentPath}/${artifactName}`);
+  }
+
+  if (wildflyConfig.mode === 'domain') {
+    return [`${getRemoteTempDir(clientConfig)}/${artifactName}`];
+  }
+
+  return [`${clientConfig.wildfly_path}/${wildflyConfig.mode}/deployments/${artifactName}`];
+}
+
+function createRemoteChecks(wildflyConfig, clientConfig, moduleInfo = null) {
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const checks = [
+    {
+      title: `SSH connection to ${target}`,
+      command: 'ssh',
+      args: [target, 'true']
+    },
+    {
+      title: `WildFly path ${clientConfig.wildfly_path} exists`,
+      command: 'ssh',
+      args: [target, `test -d ${shellQuote(clientConfig.wildfly_path)}`]
+    }
+  ];
+
+  if (moduleInfo?.isGlobalModule) {
+    moduleInfo.deploymentPaths.forEach((deploymentPath) => {
+      const modulesPath = `${clientConfig.wildfly_path}/${deploymentPath}`;
+      checks.push({
+        title: `Module dir ${modulesPath} is writable`,
+        command: 'ssh',
+        args: [target, `test -d ${shellQuote(modulesPath)} && test -w ${shellQuote(modulesPath)}`]
+      });
+    });
+  } else if (wildflyConfig.mode === 'standalone') {
+    const deploymentsPath = `${clientConfig.wildfly_path}/${wildflyConfig.mode}/deployments`;
+    checks.push({
+      title: `Deployments dir ${deploymentsPath} is writable`,
+      command: 'ssh',
+      args: [target, `test -d ${shellQuote(deploymentsPath)} && test -w ${shellQuote(deploymentsPath)}`]
+    });
+  }
+
+  if (wildflyConfig.mode === 'domain') {
+    const remoteTempDir = getRemoteTempDir(clientConfig);
+    checks.push({
+      title: `Remote temp dir ${remoteTempDir} is writable`,
+      command: 'ssh',
+      args: [target, `test -d ${shellQuote(remoteTempDir)} && test -w ${shellQuote(remoteTempDir)}`]
+    });
+  }
+
+  return checks;
+}
+
+async function runRemoteChecks(checks, run = runCommand) {
+  const results = [];
+
+  for (const check of checks) {
+    try {
+      await run(check.command, check.args, { stdio: 'ignore' });
+      results.push({ check, passed: true });
+    } catch (error) {
+      results.push({ check, passed: false, error: error.message });
+    }
+  }
+
+  return results;
+}
+
+function createRemoteSteps(artifactPath, wildflyConfig, clientConfig, moduleInfo) {
+  if (moduleInfo?.isGlobalModule) {
+    return createRemoteGlobalModuleSteps(artifactPath, clientConfig, moduleInfo);
+  }
+
+  if (wildflyConfig.mode === 'domain') {
+    return createRemoteDomainSteps(artifactPath, wildflyConfig, clientConfig);
+  }
+
+  return createRemoteStandaloneSteps(artifactPath, wildflyConfig, clientConfig);
+}
+
+function createRemoteStandaloneSteps(artifactPath, wildflyConfig, clientConfig) {
+  const artifactName = path.basename(artifactPath);
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const sudo = clientConfig.user === 'root' ? '' : 'sudo ';
+  const remoteArtifactPath = `${getRemoteTempDir(clientConfig)}/${artifactName}`;
+  const deployedPath = `${clientConfig.wildfly_path}/${wildflyConfig.mode}/deployments/${artifactName}`;
+
+  // Copy next to the server first so the scanner never sees a partial upload.
+  return [
+    {
+      title: 'Copy artifact to remote temp dir',
+      command: 'scp',
+      args: [artifactPath, `${target}:${remoteArtifactPath}`]
+    },
+    {
+      title: 'Move artifact into deployments',
+      command: 'ssh',
+      args: [target, `${sudo}mv -f ${shellQuote(remoteArtifactPath)} ${shellQuote(deployedPath)}`]
+    },
+    {
+      title: 'Trigger hot deployment',
+      command: 'ssh',
+      args: [target, `${sudo}touch ${shellQuote(`${deployedPath}.dodeploy`)}`]
+    },
+    {
+      title: 'Remove remote temp file',
+      command: 'ssh',
+      args: [target, `${sudo}rm -f ${shellQuote(remoteArtifactPath)}`],
+      always: true
+    }
+  ];
+}
+
+function createRemoteGlobalModuleSteps(artifactPath, clientConfig, moduleInfo) {
+  const artifactName = path.basename(artifactPath);
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const sudo = clientConfig.user === 'root' ? '' : 'sudo ';
+  const remoteArtifactPath = `${getRemoteTempDir(clientConfig)}/${artifactName}`;
+  moduleInfo.deploymentPaths.forEach(assertModulePath);
+  const installSteps = moduleInfo.deploymentPaths.map((deploymentPath) => {
+    const modulePath = `${clientConfig.wildfly_path}/${deploymentPath}`;
+    return {
+      title: `Install into ${deploymentPath}`,
+      command: 'ssh',
+      args: [target, `${sudo}mkdir -p ${shellQuote(modulePath)} && ${sudo}cp -f ${shellQuote(remoteArtifactPath)} ${shellQuote(`${modulePath}/${artifactName}`)}`]
+    };
+  });
+
+  return [
+    {
+      title: 'Copy artifact to remote temp dir',
+      command: 'scp',
+      args: [artifactPath, `${target}:${remoteArtifactPath}`]
+    },
+    ...installSteps,
+    createRemoteRestartStep(clientConfig, 'Restart WildFly (required for global modules)'),
+    {
+      title: 'Remove remote temp file',
+      command: 'ssh',
+      args: [target, `${sudo}rm -f ${shellQuote(remoteArtifactPath)}`],
+      always: true
+    }
+  ];
+}
+
+function createRemoteRestartStep(clientConfig, title = 'Restart WildFly') {
+  if (!clientConfig.restart_cmd) {
+    throw new Error(`Missing restart_cmd for ${clientConfig.host}`);
+  }
+
+  return {
+    title,
+    command: 'ssh',
+    args: [`${clientConfig.user}@${clientConfig.host}`, clientConfig.restart_cmd]
+  };
+}
+
+function createRemoteDomainSteps(artifactPath, wildflyConfig, clientConfig) {
+  const artifactName = path.basename(artifactPath);
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const sudo = clientConfig.user === 'root' ? '' : 'sudo ';
+  const remoteTempDir = getRemoteTempDir(clientConfig);
+  const remoteArtifactPath = `${remoteTempDir}/${artifactName}`;
+  const cli = `${sudo}${shellQuote(`${clientConfig.wildfly_path}/bin/jboss-cli.sh`)} --connect`;
+  const serverGroups = `--server-groups=${wildflyConfig.serverGroup}`;
+
+  if (!wildflyConfig.serverGroup) {
+    throw new Error('Missing server_groups in configuration for domain mode');
+  }
+
+  return [
+    {
+      title: 'Copy artifact to remote temp dir',
+      command: 'scp',
+      // scp's SFTP transfer does not pass the remote path through a shell.
+      args: [artifactPath, `${target}:${remoteArtifactPath}`]
+    },
+    {
+      title: 'Undeploy existing deployment',
+      command: 'ssh',
+      args: [target, `${cli} --commands=${shellQuote(`undeploy ${cliQuote(artifactName)} ${serverGroups}`)}`],
+      allowFailure: true
+    },
+    {
+      title: 'Deploy to server group',
+      command: 'ssh',
+      args: [target, `${cli} --commands=${shellQuote(`deploy ${cliQuote(remoteArtifactPath)} --name=${cliQuote(artifactName)} --runtime-name=${cliQuote(artifactName)} ${serverGroups}`)}`]
+    },
+    {
+      title: 'Verify deployment',
+      command: 'ssh',
+      args: [target, `${cli} --commands=${shellQuote(`deployment-info --name=${cliQuote(artifactName)}`)}`]
+    },
+    {
+      title: 'Remove remote temp file',
+      command: 'ssh',
+      args: [target, `${sudo}rm -f ${shellQuote(remoteArtifactPath)}`],
+      always: true
+    }
+  ];
+}
+
+// Streams over plain ssh: the sftp transport cannot run commands.
+function createRemoteLogCommand(wildflyConfig, clientConfig, lines) {
+  if (clientConfig.transport === 'sftp') {
+    throw new Error(`Client ${clientConfig.host} uses transport 'sftp', which cannot tail the server log; use ssh`);
+  }
+
+  const target = `${clientConfig.user}@${clientConfig.host}`;
+  const sudo = clientConfig.user === 'root' ? '' : 'sudo ';
+  const logPath = getServerLogPath(clientConfig.wildfly_path, wildflyConfig.mode, clientConfig.log_dir, path.posix.join);
+
+  return {
+    command: 'ssh',
+    args: [target, `${sudo}tail -n ${lines} -F ${shellQuote(logPath)}`]
+  };
+}
+
+async function withRemoteRunner(clientConfig, run, callback) {
+  if (run || clientConfig.transport !== 'sftp') {
+    return callback(run || runCommand);
+  }
+
+  const runner = await createSftpRunner(clientConfig);
+  try {
+    return await callback(runner.run);
+  } finally {
+    runner.close();
+  }
+}
+
+function formatRemoteStep(step) {
+  if (step.command === 'ssh') {
+    const [target, remoteCommand] = step.args;
+    return `ssh ${target} ${quoteRemoteCommand(remoteCommand)}`;
+  }
+
+  return formatCommand(step.command, step.args);
+}
+
+async function executeRemoteSteps(steps, run = runCommand) {
+  const results = [];
+  let failed = false;
+
+  for (const [index, step] of steps.entries()) {
+    if (failed && !step.always) {
+      results.push({ step, status: 'skipped' });
+      continue;
+    }
+
+    printInfo(`${index + 1}. ${step.title}`);
+    printCommand(formatRemoteStep(step));
+
+    try {
+      await run(step.command, step.args, { stdio: getCommandStdio() });
+      results.push({ step, status: 'passed' });
+    } catch (error) {
+      if (step.allowFailure) {
+        results.push({ step, status: 'ignored', error: error.message });
+        continue;
+      }
+
+      failed = true;
+      results.push({ step, status: 'failed', error: error.message });
+    }
+  }
+
+  return {
+    results,
+    succeeded: !failed
+  };
+}
+
 export {
-  createRemoteDeploymentPlan
+  createRemoteDeploymentPlan,
+  getRemoteTempDir,
+  getRemoteTargetPaths,
+  createRemoteChecks,
+  runRemoteChecks,
+  createRemoteSteps,
+  createRemoteStandaloneSteps,
+  createRemoteGlobalModuleSteps,
+  createRemoteLogCommand,
+  createRemoteRestartStep,
+  createRemoteDomainSteps,
+  executeRemoteSteps,
+  withRemoteRunner
 };
@@ -1,43 +1,83 @@
+import path from 'node:path';
 import prettyBytes from 'pretty-bytes';
 import ms from 'ms';
 import {
   formatDetail,
+  glyph,
   joinDetails,
   printCommand,
+  printError,
   printInfo,
+  printPlain,
   printSection,
   printSuccess,
+  printTable,
   printWarning
 } from '../output.js';
+import { formatServerGroups, getDeploymentsDir, getLocalTargetPaths, getRestartCommand } from './wildfly.js';
+import { showRestartMatches } from '../build/reporting.js';
 
 function showDeploymentPlan(plan) {
   printSection('deploy', [
     formatDetail('project', plan.project),
     formatDetail('module', plan.module.artifactId),
-    formatDetail('type', plan.module.isGlobalModule ? 'global-module' : 'application')
-  ]);
-  printInfo(formatDetail('artifact', plan.artifactPath));
+    formatDetail('type', getDeploymentTypeLabel(plan))
+  ], { project: plan.project, module: plan.module.artifactId, artifact: path.basename(plan.artifactPath) });
+  printInfo(formatDetail('artifact', plan.artifactPath), { phase: 'deploy', artifact: path.basename(plan.artifactPath) });
+  getLocalTargetPaths(plan.artifactPath, plan.wildflyConfig, plan.module, plan.deployOptions).forEach((targetPath) => {
+    printInfo(formatDetail('target', targetPath), { phase: 'deploy', target: targetPath });
+  });
   printInfo(joinDetails([
     formatDetail('mode', plan.wildflyConfig.mode),
     formatDetail('root', plan.wildflyConfig.root),
     plan.wildflyConfig.mode === 'domain'
-      ? formatDetail('group', plan.wildflyConfig.serverGroup)
+      ? formatServerGroups(plan.wildflyConfig)
       : ''
+  ]), { phase: 'deploy', mode: plan.wildflyConfig.mode });
+  showGitInfo(plan.git);
+}
+
+// A dirty tree usually means the artifact was built from uncommitted code.
+function showGitInfo(git) {
+  if (!git) {
+    return;
+  }
+
+  printInfo(joinDetails([
+    formatDetail('branch', git.branch),
+    formatDetail('commit', git.commit),
+    git.dirty ? 'dirty' : 'clean'
   ]));
+
+  if (git.dirty) {
+    printWarning(`working tree has uncommitted changes: the artifact may not match commit ${git.commit}`);
+  }
+}
+
+function getDeploymentTypeLabel(plan) {
+  if (!plan.module.isGlobalModule) {
+    return 'application';
+  }
+
+  return plan.deployOptions?.alsoGlobal ? 'application + global-module' : 'global-module';
 }
 
 function showDeploymentSuccess() {
   printSuccess('WildFly deployment finished');
 }
 
+function showDryRunNotice() {
+  printWarning('dry run: no files were copied and jboss-cli was not invoked');
+}
+
 function showDeploymentSummary(result) {
-  result.endTime = new Date();
-  const duration = ms(result.endTime - result.startTime);
+  result.endTime = result.endTime || new Date();
+  const duration = result.endTime - result.startTime;
 
   printSection('summary', [
     formatDetail('actions', result.actions.length),
-    formatDetail('duration', duration)
-  ]);
+    formatDetail('duration', ms(duration))
+  ], { actions: result.actions.length, duration });
 
   for (const action of result.actions) {
     switch (action.type) {
@@ -48,10 +88,26 @@ function showDeploymentSummary(result) {
         printInfo(`copied ${prettyBytes(action.size)}`);
         printInfo(`  from: ${action.source}`);
         printInfo(`  to:   ${action.dest}`);
+        if (action.checksum) {
+          printInfo(`  sha256: ${action.checksum}`);
+        }
         break;
       case 'marker_created':
         printInfo(`created marker: ${action.path}`);
         break;
+      case 'marker_removed':
+        printInfo(`removed marker: ${action.path}`);
+        break;
+      case 'file_removed':
+        printInfo(`removed: ${action.path}`);
+        break;
+      case 'file_written':
+        printInfo(`wrote: ${action.path}`);
+        break;
+      case 'backup_created':
+        printInfo(`backed up: ${action.source}`);
+        printInfo(`  to:   ${action.path}`);
+        break;
       case 'cli_deploy':
         printInfo(`executed via: ${action.cliPath}`);
         printInfo('commands');
@@ -61,18 +117,100 @@ function showDeploymentSummary(result) {
 }
 }
 
-function showDeploymentRestartGuidance(wildflyConfig, moduleInfo) {
-  const status = moduleInfo.isGlobalModule ? 'required' : 'verify deployment';
-  const reason = moduleInfo.isGlobalModule
-    ? 'global modules need a WildFly restart'
-    : 'normal deployments usually hot deploy';
-  const restartCommand = wildflyConfig.mode === 'standalone'
-    ? `${wildflyConfig.root}/bin/shutdown.sh --restart`
-    : `${wildflyConfig.root}/bin/domain.sh --restart`;
+function showDeploymentRestartGuidance(wildflyConfig, restartDecision) {
+  const statusLabels = {
+    required: 'required',
+    recommended: 'recommended',
+    'not-required': 'verify deployment',
+    unknown: 'check manually'
+  };
+  const restartCommand = getRestartCommand(wildflyConfig);
+
+  printSection('restart', [statusLabels[restartDecision.status] || statusLabels.unknown, restartDecision.reason], { severity: restartDecision.status });
+
+  showRestartMatches(restartDecision.matches);
 
-  printSection('restart', [status, reason]);
   printInfo('restart command');
-  printCommand(restartCommand);
+  printCommand(restartCommand.display);
+}
+
+function showAccessUrl(accessUrl) {
+  if (accessUrl) {
+    printInfo(formatDetail('url', accessUrl), { url: accessUrl });
+  }
+}
+
+function showWarmupReport(warmup) {
+  printSection('warmup', [`${warmup.succeeded}/${warmup.total} requests succeeded`], { succeeded: warmup.succeeded, total: warmup.total });
+
+  warmup.results
+    .filter((result) => !result.ok)
+    .forEach((result) => printWarning(
+      joinDetails([result.url, result.status ? `HTTP ${result.status}` : result.error]),
+      { phase: 'warmup', url: result.url, status: result.status }
+    ));
+}
+
+function showHealthCheckReport(healthCheck) {
+  const attempts = formatDetail('attempts', healthCheck.attempts);
+
+  const fields = { url: healthCheck.url, status: healthCheck.status, attempts: healthCheck.attempts, healthy: healthCheck.healthy };
+
+  if (healthCheck.healthy) {
+    printSection('health check', [`HTTP ${healthCheck.status}`, attempts], fields);
+    return;
+  }
+
+  printSection('health check', ['unhealthy', attempts], fields);
+  printWarning(joinDetails([
+    healthCheck.url,
+    healthCheck.status ? `HTTP ${healthCheck.status}, expected ${healthCheck.expectedStatus}` : healthCheck.error
+  ]));
+}
+
+function showRestartAction(restartAction) {
+  if (!restartAction.restart) {
+    printInfo(joinDetails(['restart skipped', restartAction.reason]));
+    return;
+  }
+
+  if (restartAction.forced) {
+    printWarning(restartAction.reason);
+    return;
+  }
+
+  printInfo(joinDetails(['restarting WildFly', restartAction.reason]));
+}
+
+function showUndeployPlan(artifactName, wildflyConfig) {
+  printSection('undeploy', [
+    formatDetail('artifact', artifactName),
+    formatDetail('mode', wildflyConfig.mode)
+  ], { artifact: artifactName, mode: wildflyConfig.mode });
+  printInfo(wildflyConfig.mode === 'domain'
+    ? formatServerGroups(wildflyConfig)
+    : formatDetail('target', path.join(getDeploymentsDir(wildflyConfig), artifactName)));
+}
+
+function showUndeploySuccess(artifactName) {
+  printSuccess(`undeployed ${artifactName}`, { phase: 'undeploy', artifact: artifactName });
+}
+
+function showRollbackPlan(artifactName, restores) {
+  printSection('rollback', [formatDetail('artifact', artifactName)], { artifact: artifactName });
+
+  restores.forEach(({ targetPath, backupPath }) => {
+    printInfo(formatDetail('backup', backupPath), { phase: 'rollback', backup: backupPath, target: targetPath });
+    printInfo(`  ${glyph('arrow')} ${targetPath}`);
+  });
+}
+
+function showRollbackSuccess(artifactName, isGlobalModule) {
+  printSuccess(`restored ${artifactName} from backup`, { phase: 'rollback', artifact: artifactName });
+
+  if (isGlobalModule) {
+    printWarning('global module restored: restart WildFly to load it');
+  }
 }
 
 function showRemoteDeploymentGuide(remotePlan, clientName) {
@@ -83,7 +221,7 @@ function showRemoteDeploymentGuide(remotePlan, clientName) {
     return;
   }
 
-  printSection('remote', clientName ? [formatDetail('client', clientName)] : []);
+  printSection('remote', clientName ? [formatDetail('client', clientName)] : [], { client: clientName });
 
   remotePlan.steps.forEach((step, index) => {
     printInfo(`${index + 1}. ${step.title}`);
@@ -91,10 +229,301 @@ function showRemoteDeploymentGuide(remotePlan, clientName) {
   });
 }
 
+function showRemoteDeploymentPlan(plan, clientName) {
+  printSection('remote deploy', [
+    formatDetail('client', clientName),
+    formatDetail('module', plan.module.artifactId),
+    formatServerGroups(plan.wildflyConfig)
+  ], { client: clientName, module: plan.module.artifactId, artifact: path.basename(plan.artifactPath) });
+  printInfo(formatDetail('artifact', plan.artifactPath), { phase: 'remote deploy', artifact: path.basename(plan.artifactPath) });
+  showGitInfo(plan.git);
+}
+
+function showRemoteCheckResults(results) {
+  printTable([
+    { key: 'result', label: 'RESULT' },
+    { key: 'check', label: 'CHECK' },
+    { key: 'error', label: 'ERROR' }
+  ], results.map((result) => ({
+    result: result.passed ? 'passed' : 'failed',
+    check: result.check.title,
+    error: result.error,
+    tone: result.passed ? 'success' : 'failure'
+  })));
+}
+
+function showDeployBatch(items, clientName) {
+  printSection('deploy batch', [
+    formatDetail('artifacts', items.length),
+    formatDetail('client', clientName)
+  ], { artifacts: items.length, client: clientName });
+
+  items.forEach((item) => {
+    printInfo(formatDetail('artifact', item.artifactPath), { phase: 'deploy batch', artifact: path.basename(item.artifactPath) });
+    item.targets.forEach((target) => printInfo(`  ${glyph('arrow')} ${target}`));
+  });
+}
+
+function showMultiDeploySummary(entries) {
+  printSection('deploy summary', [formatDetail('artifacts', entries.length)], { artifacts: entries.length });
+  printTable([
+    { key: 'artifact', label: 'ARTIFACT' },
+    { key: 'outcome', label: 'OUTCOME' },
+    { key: 'restart', label: 'RESTART' },
+    { key: 'health', label: 'HEALTH' },
+    { key: 'error', label: 'ERROR' }
+  ], entries.map((entry) => {
+    const restart = entry.result?.restartDecision?.status;
+    const outcome = getDeployOutcome(entry);
+
+    return {
+      artifact: path.basename(entry.artifactPath),
+      outcome,
+      restart,
+      health: formatHealth(entry.result?.healthCheck),
+      error: entry.error?.message,
+      tone: getDeployTone(outcome, restart)
+    };
+  }));
+}
+
+function formatHealth(healthCheck) {
+  if (!healthCheck) {
+    return undefined;
+  }
+
+  return healthCheck.healthy ? 'healthy' : 'unhealthy';
+}
+
+function getDeployOutcome(entry) {
+  if (entry.error) {
+    return 'failed';
+  }
+
+  return entry.result ? 'deployed' : 'cancelled';
+}
+
+function getDeployTone(outcome, restart) {
+  if (outcome !== 'deployed') {
+    return outcome === 'failed' ? 'failure' : 'warning';
+  }
+
+  return restart === 'required' ? 'warning' : 'success';
+}
+
+function showDeployResultJson(entries) {
+  const reports = entries.map(getDeployReport);
+  printPlain(JSON.stringify(reports.length === 1 ? reports[0] : { deployments: reports }));
+}
+
+function getDeployReport(entry) {
+  const report = entry.error?.report || entry.result?.report || {
+    artifact: path.basename(entry.artifactPath),
+    succeeded: false
+  };
+
+  if (entry.error) {
+    return { ...report, error: entry.error.message };
+  }
+
+  return entry.result ? report : { ...report, cancelled: true };
+}
+
+function showDoctorResults(checks) {
+  printSection('doctor', [formatDetail('checks', checks.length)]);
+
+  for (const check of checks) {
+    const message = joinDetails([check.title, check.detail]);
+
+    if (check.passed) {
+      printSuccess(message);
+    } else if (check.soft) {
+      printWarning(message);
+    } else {
+      printError(message);
+    }
+
+    if (check.hint) {
+      printInfo(`  hint: ${check.hint}`);
+    }
+  }
+}
+
+function showConfigValidation({ files, projects }) {
+  printSection('config validate', [
+    formatDetail('files', ['built-in', ...files].join(' + ')),
+    formatDetail('projects', projects.length)
+  ]);
+
+  for (const project of projects) {
+    const { wildflyConfig } = project;
+    const summary = joinDetails([
+      project.name,
+      formatDetail('mode', project.mode),
+      formatDetail('root', wildflyConfig?.root),
+      project.mode === 'domain' && wildflyConfig?.serverGroups.length > 0 ? formatServerGroups(wildflyConfig) : null,
+      formatDetail('clients', project.clients.join(', ') || 'none')
+    ]);
+
+    if (project.problems.length === 0) {
+      printSuccess(summary);
+    } else {
+      printError(summary);
+    }
+
+    project.globalModules.forEach(({ artifactId, deploymentPaths }) => {
+      const targets = deploymentPaths.map((deploymentPath) => (wildflyConfig?.root ? path.join(wildflyConfig.root, deploymentPath) : deploymentPath));
+      printInfo(`  ${formatDetail('global module', artifactId)} ${glyph('arrow')} ${targets.join(', ')}`);
+    });
+    project.problems.forEach(printError);
+  }
+}
+
+const DEPLOYMENT_STATE_TONES = {
+  deployed: 'success',
+  enabled: 'success',
+  failed: 'failure'
+};
+
+function showDeploymentStatus(wildflyConfig, deployments) {
+  printSection('status', [
+    formatDetail('mode', wildflyConfig.mode),
+    wildflyConfig.mode === 'domain'
+      ? formatServerGroups(wildflyConfig)
+      : formatDetail('dir', getDeploymentsDir(wildflyConfig)),
+    formatDetail('deployments', deployments.length)
+  ]);
+
+  if (deployments.length === 0) {
+    printInfo('no deployments');
+    return;
+  }
+
+  if (wildflyConfig.mode === 'domain') {
+    printTable([
+      ...(wildflyConfig.serverGroups.length > 1 ? [{ key: 'group', label: 'GROUP' }] : []),
+      { key: 'name', label: 'NAME' },
+      { key: 'runtimeName', label: 'RUNTIME-NAME' },
+      { key: 'state', label: 'STATE' }
+    ], deployments.map((deployment) => ({ ...deployment, tone: DEPLOYMENT_STATE_TONES[deployment.state] || 'warning' })));
+    return;
+  }
+
+  printTable([
+    { key: 'name', label: 'ARTIFACT' },
+    { key: 'state', label: 'STATE' },
+    { key: 'size', label: 'SIZE' },
+    { key: 'modified', label: 'MODIFIED' }
+  ], deployments.map((deployment) => ({
+    name: deployment.name,
+    state: deployment.state,
+    size: deployment.size === null ? 'dir' : prettyBytes(deployment.size),
+    modified: deployment.modified.toLocaleString(),
+    tone: DEPLOYMENT_STATE_TONES[deployment.state] || 'warning'
+  })));
+}
+
+const HISTORY_OUTCOME_TONES = {
+  deployed: 'success',
+  undeployed: 'success',
+  'rolled-back': 'warning',
+  failed: 'failure'
+};
+
+function showDeployHistory(auditLog, entries) {
+  printSection('history', [
+    formatDetail('log', auditLog || 'disabled'),
+    formatDetail('entries', entries.length)
+  ]);
+
+  if (entries.length === 0) {
+    printInfo('no deploys recorded');
+    return;
+  }
+
+  printTable([
+    { key: 'time', label: 'TIME' },
+    { key: 'user', label: 'USER' },
+    { key: 'project', label: 'PROJECT' },
+    { key: 'artifact', label: 'ARTIFACT' },
+    { key: 'target', label: 'TARGET' },
+    { key: 'outcome', label: 'OUTCOME' },
+    { key: 'restart', label: 'RESTART' },
+    { key: 'note', label: 'NOTE' }
+  ], entries.map((entry) => ({
+    time: new Date(entry.time).toLocaleString(),
+    user: entry.host ? `${entry.user}@${entry.host}` : entry.user,
+    project: entry.project,
+    artifact: entry.action ? `${entry.action} ${entry.artifact}` : entry.artifact,
+    target: entry.client || 'local',
+    outcome: entry.outcome,
+    restart: entry.restartSeverity,
+    note: entry.note || entry.error,
+    tone: HISTORY_OUTCOME_TONES[entry.outcome] || 'warning'
+  })));
+}
+
+const REMOTE_STEP_TONES = {
+  passed: 'success',
+  failed: 'failure',
+  ignored: 'warning'
+};
+
+function showRemoteExecutionSummary(execution, clientName) {
+  const counts = execution.results.reduce((acc, result) => {
+    acc[result.status] = (acc[result.status] || 0) + 1;
+    return acc;
+  }, {});
+
+  printSection('remote summary', [
+    formatDetail('client', clientName),
+    formatDetail('passed', counts.passed),
+    formatDetail('failed', counts.failed),
+    formatDetail('skipped', counts.skipped),
+    formatDetail('ignored', counts.ignored)
+  ], { client: clientName, passed: counts.passed, failed: counts.failed, skipped: counts.skipped, ignored: counts.ignored });
+
+  printTable([
+    { key: 'status', label: 'STATUS' },
+    { key: 'step', label: 'STEP' },
+    { key: 'error', label: 'ERROR' }
+  ], execution.results.map((result) => ({
+    status: result.status,
+    step: result.step.title,
+    error: result.error,
+    tone: REMOTE_STEP_TONES[result.status]
+  })));
+
+  if (execution.succeeded) {
+    printSuccess(`remote deployment to ${clientName} finished`, { phase: 'remote deploy', client: clientName });
+  } else {
+    printWarning(`remote deployment to ${clientName} failed`, { phase: 'remote deploy', client: clientName });
+  }
+}
+
 export {
   showDeploymentPlan,
   showDeploymentSuccess,
   showDeploymentSummary,
+  showDryRunNotice,
   showDeploymentRestartGuidance,
-  showRemoteDeploymentGuide
+  showRestartAction,
+  showWarmupReport,
+  showHealthCheckReport,
+  showAccessUrl,
+  showRemoteDeploymentGuide,
+  showRemoteDeploymentPlan,
+  showRemoteCheckResults,
+  showRemoteExecutionSummary,
+  showDeployBatch,
+  showMultiDeploySummary,
+  showDeployResultJson,
+  showDeploymentStatus,
+  showDeployHistory,
+  showDoctorResults,
+  showConfigValidation,
+  showRollbackPlan,
+  showRollbackSuccess,
+  showUndeployPlan,
+  showUndeploySuccess
 };
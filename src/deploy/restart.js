@@ -0,0 +1,60 @@
+import { execFileSync } from 'node:child_process';
+import { RESTART_STATUSES } from '../build/restart.js';
+import { assertCliPath, createCliTimeoutError, getRestartCommand, isCliTimeout, redactCredentials } from './wildfly.js';
+import { JmwError } from '../errors.js';
+import { formatCommand, getCommandStdio, printDebug } from '../output.js';
+
+// `restart: false` comes from --no-restart; --force-restart implies --restart
+// but still leaves the server alone when the analysis finds nothing to do.
+function resolveRestartAction(restartDecision, options = {}) {
+  if (options.restart === false) {
+    return { restart: false, forced: false, reason: 'restart disabled (--no-restart)' };
+  }
+
+  if (!options.restart && !options.forceRestart) {
+    return { restart: false, forced: false, reason: 'restart not requested' };
+  }
+
+  if (restartDecision.status === RESTART_STATUSES.REQUIRED) {
+    return { restart: true, forced: false, reason: restartDecision.reason };
+  }
+
+  if (options.forceRestart) {
+    return restartDecision.status === RESTART_STATUSES.NOT_REQUIRED
+      ? { restart: false, forced: false, reason: `--force-restart ignored, no restart needed (${restartDecision.reason})` }
+      : { restart: true, forced: true, reason: `restart forced by user (analysis: ${restartDecision.status})` };
+  }
+
+  return { restart: false, forced: false, reason: `restart ${restartDecision.status}` };
+}
+
+function restartWildfly(wildflyConfig, run = execFileSync) {
+  if (!wildflyConfig.restartCmd) {
+    assertCliPath(wildflyConfig);
+  }
+  const { command, args, display } = getRestartCommand(wildflyConfig);
+  printDebug(`exec ${wildflyConfig.restartCmd ? formatCommand(command, args) : display}`);
+
+  try {
+    run(command, args, {
+      stdio: getCommandStdio(),
+      // A configured restart_cmd may legitimately wait for the whole server.
+      timeout: wildflyConfig.restartCmd ? undefined : wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+  } catch (error) {
+    if (isCliTimeout(error)) {
+      throw createCliTimeoutError('restart', wildflyConfig.cliTimeout, { phase: 'restart' });
+    }
+
+    throw new JmwError(`WildFly restart failed: ${redactCredentials(error.message, wildflyConfig)}`, {
+      code: 'RESTART_FAILED',
+      phase: 'restart'
+    });
+  }
+}
+
+export {
+  resolveRestartAction,
+  restartWildfly
+};
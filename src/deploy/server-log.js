@@ -0,0 +1,41 @@
+// Message codes logged by recent WildFly releases (26+) when a deployment
+// starts or fails. Older or newer servers can override them via log_patterns.
+const DEFAULT_LOG_PATTERNS = Object.freeze({
+  success: 'WFLYSRV0010|WFLYSRV0016|WFLYUT0021',
+  failure: 'WFLYCTL0013|WFLYCTL0080|WFLYSRV0021|WFLYSRV0153'
+});
+
+function getLogPatterns(projectConfig) {
+  const patterns = { ...DEFAULT_LOG_PATTERNS, ...projectConfig.log_patterns };
+
+  return {
+    success: compileLogPattern('success', patterns.success),
+    failure: compileLogPattern('failure', patterns.failure)
+  };
+}
+
+function compileLogPattern(name, pattern) {
+  try {
+    return new RegExp(pattern);
+  } catch (error) {
+    throw new Error(`Invalid log_patterns.${name} '${pattern}': ${error.message}`);
+  }
+}
+
+function classifyLogLine(line, logPatterns) {
+  if (logPatterns.failure.test(line)) {
+    return 'failure';
+  }
+
+  if (logPatterns.success.test(line)) {
+    return 'success';
+  }
+
+  return null;
+}
+
+export {
+  DEFAULT_LOG_PATTERNS,
+  getLogPatterns,
+  classifyLogLine
+};
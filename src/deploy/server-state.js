@@ -0,0 +1,160 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import { execFileSync } from 'node:child_process';
+import { printDebug } from '../output.js';
+import {
+  assertCliPath,
+  debugCliInvocation,
+  getCliInvocation,
+  getConnectArgs,
+  getServerLogPath,
+  isCliTimeout,
+  redactCredentials
+} from './wildfly.js';
+
+// jboss-modules entry points and the -D[...] process names that WildFly's
+// launch scripts put on the java command line.
+const MODE_MARKERS = {
+  standalone: ['org.jboss.as.standalone', '-D[Standalone]'],
+  domain: ['org.jboss.as.process-controller', 'org.jboss.as.host-controller', '-D[Process Controller]', '-D[Host Controller]']
+};
+
+// Returns [{ pid, args }], or null where processes cannot be listed.
+function listProcesses(platform = process.platform) {
+  if (platform === 'win32') {
+    return null;
+  }
+
+  if (platform === 'linux' && fs.existsSync('/proc')) {
+    return fs.readdirSync('/proc')
+      .filter((entry) => /^\d+$/.test(entry))
+      .map((pid) => {
+        try {
+          return { pid: Number(pid), args: fs.readFileSync(`/proc/${pid}/cmdline`, 'utf8').split('\0').join(' ').trim() };
+        } catch {
+          return null;
+        }
+      })
+      .filter((entry) => entry && entry.args);
+  }
+
+  try {
+    return execFileSync('ps', ['-axww', '-o', 'pid=,args='], { encoding: 'utf8', stdio: ['ignore', 'pipe', 'ignore'] })
+      .split('\n')
+      .map((line) => line.trim().match(/^(\d+)\s+(.*)$/))
+      .filter(Boolean)
+      .map(([, pid, args]) => ({ pid: Number(pid), args }));
+  } catch {
+    return null;
+  }
+}
+
+// WildFly java processes started from this root, with the mode they run in.
+function findWildflyProcesses(wildflyRoot, processes = listProcesses()) {
+  if (!processes) {
+    return null;
+  }
+
+  const root = path.resolve(wildflyRoot);
+  return processes
+    .filter(({ args }) => {
+      const homeDir = args.match(/-Djboss\.home\.dir=(\S+)/)?.[1];
+      return homeDir && path.resolve(homeDir) === root;
+    })
+    .map(({ pid, args }) => ({
+      pid,
+      mode: Object.keys(MODE_MARKERS).find((mode) => MODE_MARKERS[mode].some((marker) => args.includes(marker))) || null
+    }))
+    .filter((entry) => entry.mode);
+}
+
+// A running server is conclusive; otherwise the most recently written boot
+// log tells which mode the installation was last started in.
+function detectWildflyMode(wildflyConfig, processes) {
+  const running = findWildflyProcesses(wildflyConfig.root, processes) || [];
+  const runningModes = [...new Set(running.map((entry) => entry.mode))];
+
+  if (runningModes.length === 1) {
+    return { mode: runningModes[0], source: `running process ${running[0].pid}` };
+  }
+
+  const [lastLog] = Object.entries(getModeLogPaths(wildflyConfig))
+    .map(([mode, logPath]) => ({ mode, logPath }))
+    .filter(({ logPath }) => fs.existsSync(logPath))
+    .map((entry) => ({ ...entry, mtime: fs.statSync(entry.logPath).mtimeMs }))
+    .sort((a, b) => b.mtime - a.mtime);
+
+  return lastLog ? { mode: lastLog.mode, source: `last written ${lastLog.logPath}` } : null;
+}
+
+// Each mode writes its boot log under its own base directory; a log_dir
+// only moves the log of the configured mode.
+function getModeLogPaths(wildflyConfig) {
+  return {
+    standalone: getServerLogPath(wildflyConfig.root, 'standalone', wildflyConfig.mode === 'standalone' ? wildflyConfig.logDir : undefined),
+    domain: path.join(wildflyConfig.root, 'domain', 'log', 'host-controller.log')
+  };
+}
+
+// Returns a warning when the configured mode contradicts the detected one;
+// wildfly_mode still decides how jmw deploys.
+function checkWildflyMode(wildflyConfig, processes) {
+  const detected = detectWildflyMode(wildflyConfig, processes);
+  printDebug(detected ? `detected ${detected.mode} mode from ${detected.source}` : `could not detect the mode of ${wildflyConfig.root}`);
+
+  if (!detected || detected.mode === wildflyConfig.mode) {
+    return null;
+  }
+
+  return `wildfly_mode is ${wildflyConfig.mode}, but WildFly at ${wildflyConfig.root} appears to run in ${detected.mode} mode (${detected.source}). Deploying as ${wildflyConfig.mode}; fix wildfly_mode if that is wrong`;
+}
+
+// Returns { running, detail }; running is null when it cannot be told.
+// Standalone deploys only need the scanner, so a server process is enough;
+// domain deploys need the controller to answer. The domain root has no
+// server-state attribute, so launch-type is read there instead.
+function checkWildflyRunning(wildflyConfig, { run = execFileSync, processes } = {}) {
+  if (wildflyConfig.mode === 'standalone') {
+    const running = findWildflyProcesses(wildflyConfig.root, processes);
+
+    if (!running) {
+      return { running: null, detail: 'processes cannot be listed on this platform' };
+    }
+
+    return running.length > 0
+      ? { running: true, detail: `process ${running.map((entry) => entry.pid).join(', ')}` }
+      : { running: false, detail: `no java process runs with -Djboss.home.dir=${wildflyConfig.root}` };
+  }
+
+  const invocation = getCliInvocation(assertCliPath(wildflyConfig), [
+    ...getConnectArgs(wildflyConfig),
+    '--commands=:read-attribute(name=launch-type)'
+  ]);
+  debugCliInvocation(invocation, wildflyConfig);
+
+  try {
+    run(invocation.command, invocation.args, {
+      stdio: 'pipe',
+      encoding: 'utf8',
+      timeout: wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+
+    return { running: true, detail: 'controller answered' };
+  } catch (error) {
+    if (isCliTimeout(error)) {
+      return { running: false, detail: 'the controller did not answer before cli_timeout' };
+    }
+
+    const failure = `${error.stderr || ''}${error.stdout || ''}`.trim().split('\n').filter(Boolean).pop() || error.message;
+    return { running: false, detail: redactCredentials(failure, wildflyConfig) };
+  }
+}
+
+export {
+  listProcesses,
+  findWildflyProcesses,
+  detectWildflyMode,
+  checkWildflyMode,
+  checkWildflyRunning
+};
@@ -0,0 +1,207 @@
+import crypto from 'node:crypto';
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { JmwError } from '../errors.js';
+import { getCommandStreams, printDebug } from '../output.js';
+
+const DEFAULT_KNOWN_HOSTS = path.join(os.homedir(), '.ssh', 'known_hosts');
+
+// Runs remote steps over a single in-process SSH connection (ssh2) so that
+// --remote works without scp/ssh binaries, e.g. on Windows workstations.
+async function createSftpRunner(clientConfig) {
+  const { Client } = await import('ssh2');
+  const connection = new Client();
+  let hostKeyProblem = null;
+  let connectionError = null;
+
+  await new Promise((resolve, reject) => {
+    connection
+      .once('ready', resolve)
+      .once('error', (error) => reject(new JmwError(`SSH connection to ${clientConfig.host} failed: ${hostKeyProblem || error.message}`, {
+        code: hostKeyProblem ? 'HOST_KEY_UNVERIFIED' : 'REMOTE_CONNECT_FAILED',
+        phase: 'remote'
+      })))
+      .connect({
+        ...getConnectOptions(clientConfig),
+        hostVerifier: (key) => {
+          hostKeyProblem = verifyHostKey(clientConfig, key);
+          return hostKeyProblem === null;
+        }
+      });
+  });
+
+  // ssh2 emits connection failures (resets, keepalive timeouts) as 'error'
+  // events; without a listener they would crash the process mid-deploy.
+  connection.on('error', (error) => {
+    connectionError = error;
+    printDebug(`SSH connection to ${clientConfig.host} failed: ${error.message}`);
+  });
+
+  return {
+    run: (command, args, options = {}) => (connectionError
+      ? Promise.reject(new JmwError(`SSH connection to ${clientConfig.host} failed: ${connectionError.message}`, {
+        code: 'REMOTE_CONNECT_FAILED',
+        phase: 'remote'
+      }))
+      : runStep(connection, command, args, options)),
+    close: () => connection.end()
+  };
+}
+
+// Fails closed like OpenSSH with StrictHostKeyChecking: the key must match the
+// client's host_fingerprint (SHA256:..., as printed by ssh-keygen -l) or an
+// entry in known_hosts. Returns null when it does, the reason otherwise.
+function verifyHostKey(clientConfig, key, knownHostsFile = DEFAULT_KNOWN_HOSTS) {
+  if (clientConfig.host_fingerprint) {
+    const fingerprint = getHostKeyFingerprint(key);
+    return fingerprint === clientConfig.host_fingerprint
+      ? null
+      : `host key ${fingerprint} does not match host_fingerprint ${clientConfig.host_fingerprint}`;
+  }
+
+  let content;
+  try {
+    content = fs.readFileSync(knownHostsFile, 'utf8');
+  } catch {
+    return `${knownHostsFile} cannot be read; connect once with ssh or set host_fingerprint on the client`;
+  }
+
+  const entries = findKnownHostEntries(content, clientConfig.host, clientConfig.port || 22);
+  const encodedKey = key.toString('base64');
+
+  if (entries.some((entry) => entry.revoked && entry.key === encodedKey)) {
+    return `host key ${getHostKeyFingerprint(key)} is revoked in ${knownHostsFile}`;
+  }
+
+  if (entries.some((entry) => !entry.revoked && entry.key === encodedKey)) {
+    return null;
+  }
+
+  return entries.length > 0
+    ? `host key ${getHostKeyFingerprint(key)} does not match ${knownHostsFile}; the host key may have changed`
+    : `${clientConfig.host} is not in ${knownHostsFile}; connect once with ssh or set host_fingerprint on the client`;
+}
+
+function getHostKeyFingerprint(key) {
+  return `SHA256:${crypto.createHash('sha256').update(key).digest('base64').replace(/=+$/, '')}`;
+}
+
+// Lines are `[@marker] hosts keytype key`; hosts are comma-separated names,
+// `[host]:port` for other ports, or HMAC-SHA1 hashes (`|1|salt|hash`).
+function findKnownHostEntries(content, host, port) {
+  const name = port === 22 ? host : `[${host}]:${port}`;
+
+  return content.split('\n')
+    .map((line) => line.trim())
+    .filter((line) => line && !line.startsWith('#'))
+    .map((line) => line.split(/\s+/))
+    .map((fields) => (fields[0].startsWith('@') ? { marker: fields[0], fields: fields.slice(1) } : { marker: null, fields }))
+    .filter(({ marker, fields }) => marker !== '@cert-authority' && fields.length >= 3 && matchesKnownHost(fields[0], name))
+    .map(({ marker, fields }) => ({ key: fields[2], revoked: marker === '@revoked' }));
+}
+
+function matchesKnownHost(hosts, name) {
+  return hosts.split(',').some((entry) => {
+    if (entry.startsWith('|1|')) {
+      const [, , salt, hash] = entry.split('|');
+      return crypto.createHmac('sha1', Buffer.from(salt, 'base64')).update(name).digest('base64') === hash;
+    }
+
+    return entry === name;
+  });
+}
+
+function getConnectOptions(clientConfig) {
+  const options = {
+    host: clientConfig.host,
+    port: clientConfig.port || 22,
+    username: clientConfig.user
+  };
+
+  if (clientConfig.private_key) {
+    options.privateKey = fs.readFileSync(clientConfig.private_key);
+    options.passphrase = clientConfig.passphrase;
+  }
+
+  if (clientConfig.password) {
+    options.password = clientConfig.password;
+  }
+
+  if (!options.privateKey && !options.password) {
+    options.agent = process.env.SSH_AUTH_SOCK;
+  }
+
+  return options;
+}
+
+function runStep(connection, command, args, options) {
+  if (command === 'scp') {
+    const [source, destination] = args;
+    return putFile(connection, source, destination.slice(destination.indexOf(':') + 1));
+  }
+
+  if (command === 'ssh') {
+    return execRemote(connection, args[args.length - 1], options);
+  }
+
+  return Promise.reject(new Error(`sftp transport cannot run '${command}'`));
+}
+
+function putFile(connection, source, destination) {
+  return new Promise((resolve, reject) => {
+    connection.sftp((error, sftp) => {
+      if (error) {
+        reject(error);
+        return;
+      }
+
+      sftp.fastPut(source, destination, (putError) => {
+        sftp.end();
+        if (putError) {
+          reject(new Error(`sftp upload to ${destination} failed: ${putError.message}`));
+          return;
+        }
+
+        resolve();
+      });
+    });
+  });
+}
+
+function execRemote(connection, remoteCommand, options) {
+  const quiet = options.stdio === 'ignore';
+
+  return new Promise((resolve, reject) => {
+    connection.exec(remoteCommand, (error, stream) => {
+      if (error) {
+        reject(error);
+        return;
+      }
+
+      if (!quiet) {
+        const { stdout, stderr } = getCommandStreams();
+        stream.on('data', (chunk) => stdout.write(chunk));
+        stream.stderr.on('data', (chunk) => stderr.write(chunk));
+      } else {
+        stream.resume();
+        stream.stderr.resume();
+      }
+
+      stream.on('close', (code) => {
+        if (code === 0) {
+          resolve();
+          return;
+        }
+
+        reject(new Error(`remote command exited with code ${code}`));
+      });
+    });
+  });
+}
+
+export {
+  createSftpRunner,
+  verifyHostKey,
+  getHostKeyFingerprint
+};
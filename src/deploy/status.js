@@ -0,0 +1,177 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import { execFileSync } from 'node:child_process';
+import { JmwError } from '../errors.js';
+import {
+  assertCliPath,
+  createCliTimeoutError,
+  cliQuote,
+  debugCliInvocation,
+  getCliInvocation,
+  getConnectArgs,
+  getDeploymentsDir,
+  isCliTimeout,
+  redactCredentials
+} from './wildfly.js';
+
+// Checked in order: an in-flight marker wins over a stale .deployed.
+const MARKER_STATES = [
+  ['.isdeploying', 'deploying'],
+  ['.isundeploying', 'undeploying'],
+  ['.dodeploy', 'pending'],
+  ['.pending', 'pending'],
+  ['.failed', 'failed'],
+  ['.deployed', 'deployed'],
+  ['.undeployed', 'undeployed'],
+  ['.skipdeploy', 'skipped']
+];
+
+function isMarkerFile(entry) {
+  return MARKER_STATES.some(([marker]) => entry.endsWith(marker));
+}
+
+function readStandaloneDeployments(wildflyConfig) {
+  const deploymentsDir = getDeploymentsDir(wildflyConfig);
+
+  if (!fs.existsSync(deploymentsDir)) {
+    throw new JmwError(`Deployments directory not found: ${deploymentsDir}`, {
+      code: 'WILDFLY_NOT_FOUND',
+      phase: 'status'
+    });
+  }
+
+  const entries = fs.readdirSync(deploymentsDir);
+
+  return entries
+    .filter((entry) => !entry.startsWith('.') && !isMarkerFile(entry) && !/\.txt$/i.test(entry))
+    .sort()
+    .map((name) => {
+      const stats = fs.statSync(path.join(deploymentsDir, name));
+      const state = MARKER_STATES.find(([marker]) => entries.includes(`${name}${marker}`));
+
+      return {
+        name,
+        state: state ? state[1] : 'not deployed',
+        size: stats.isFile() ? stats.size : null,
+        modified: stats.mtime
+      };
+    });
+}
+
+function readDomainDeployments(wildflyConfig, run = execFileSync) {
+  if (!wildflyConfig.serverGroup) {
+    throw new Error('Missing server_groups in configuration for domain mode');
+  }
+
+  const cliPath = assertCliPath(wildflyConfig);
+
+  return wildflyConfig.serverGroups.flatMap((serverGroup) => readGroupDeployments(cliPath, wildflyConfig, serverGroup, run)
+    .map((deployment) => ({ group: serverGroup, ...deployment })));
+}
+
+function readGroupDeployments(cliPath, wildflyConfig, serverGroup, run) {
+  const invocation = getCliInvocation(cliPath, [
+    ...getConnectArgs(wildflyConfig),
+    `--commands=deployment-info --server-group=${serverGroup}`
+  ]);
+  const { command, args } = invocation;
+  debugCliInvocation(invocation, wildflyConfig);
+
+  try {
+    const output = run(command, args, {
+      stdio: 'pipe',
+      encoding: 'utf8',
+      timeout: wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+
+    return parseDeploymentInfo(output);
+  } catch (error) {
+    if (isCliTimeout(error)) {
+      throw createCliTimeoutError('deployment-info', wildflyConfig.cliTimeout, { phase: 'status' });
+    }
+
+    throw new JmwError(`Could not read deployments for ${serverGroup}: ${redactCredentials(error.stderr?.trim() || error.message, wildflyConfig)}`, {
+      code: 'STATUS_FAILED',
+      phase: 'status'
+    });
+  }
+}
+
+// `deployment-info --name=<artifact>` lists every server group with the
+// artifact's state there; groups reported as 'not added' do not have it.
+function readArtifactServerGroups(wildflyConfig, artifactName, run = execFileSync) {
+  const invocation = getCliInvocation(assertCliPath(wildflyConfig), [
+    ...getConnectArgs(wildflyConfig),
+    `--commands=deployment-info --name=${cliQuote(artifactName)}`
+  ]);
+  const { command, args } = invocation;
+  debugCliInvocation(invocation, wildflyConfig);
+
+  let output;
+  try {
+    output = run(command, args, {
+      stdio: 'pipe',
+      encoding: 'utf8',
+      timeout: wildflyConfig.cliTimeout,
+      killSignal: 'SIGKILL'
+    });
+  } catch (error) {
+    if (isCliTimeout(error)) {
+      throw createCliTimeoutError('deployment-info', wildflyConfig.cliTimeout, { phase: 'deploy', artifact: artifactName });
+    }
+
+    const failure = `${error.stdout || ''}${error.stderr || ''}`;
+    if (/WFLYCTL0216|not found/i.test(failure)) {
+      return [];
+    }
+
+    throw new JmwError(`Could not read the server groups of ${artifactName}: ${redactCredentials(failure.trim() || error.message, wildflyConfig)}`, {
+      code: 'STATUS_FAILED',
+      phase: 'deploy',
+      artifact: artifactName
+    });
+  }
+
+  return parseServerGroupStates(output)
+    .filter(({ state }) => state !== 'not added')
+    .map(({ group }) => group);
+}
+
+// deployment-info --name prints a SERVER-GROUP / STATE table.
+function parseServerGroupStates(output) {
+  const lines = String(output).split('\n').map((line) => line.trim()).filter(Boolean);
+  const headerIndex = lines.findIndex((line) => /^SERVER-GROUP\s+STATE\b/.test(line));
+
+  if (headerIndex === -1) {
+    return [];
+  }
+
+  return lines.slice(headerIndex + 1).map((line) => {
+    const [group, ...state] = line.split(/\s+/);
+    return { group, state: state.join(' ') };
+  });
+}
+
+// deployment-info prints a NAME / RUNTIME-NAME / STATE table.
+function parseDeploymentInfo(output) {
+  const lines = String(output).split('\n').map((line) => line.trim()).filter(Boolean);
+  const headerIndex = lines.findIndex((line) => /^NAME\s+RUNTIME-NAME\s+STATE\b/.test(line));
+
+  if (headerIndex === -1) {
+    return [];
+  }
+
+  return lines.slice(headerIndex + 1).map((line) => {
+    const [name, runtimeName, state] = line.split(/\s+/);
+    return { name, runtimeName, state };
+  });
+}
+
+export {
+  readStandaloneDeployments,
+  readDomainDeployments,
+  readArtifactServerGroups,
+  parseDeploymentInfo,
+  parseServerGroupStates
+};
@@ -0,0 +1,38 @@
+const DEFAULT_SERVICE_NAME = 'wildfly';
+const DEFAULT_SERVICE_USER = 'wildfly';
+
+function getSystemdRestartCommand(serviceName = DEFAULT_SERVICE_NAME, sshUser = '') {
+  const sudo = sshUser === 'root' ? '' : 'sudo ';
+  return `${sudo}systemctl restart ${serviceName}`;
+}
+
+function createSystemdUnit(wildflyRoot, mode, options = {}) {
+  const serviceName = options.serviceName || DEFAULT_SERVICE_NAME;
+  const script = mode === 'domain' ? 'domain.sh' : 'standalone.sh';
+  const shutdownCommand = mode === 'domain' ? '/host=master:shutdown' : ':shutdown';
+
+  return [
+    `# ${serviceName}.service generated by jmw${options.project ? ` for ${options.project}` : ''}`,
+    `# Install as /etc/systemd/system/${serviceName}.service, then run: systemctl daemon-reload && systemctl enable --now ${serviceName}`,
+    `# Suggested client restart_cmd: ${getSystemdRestartCommand(serviceName, options.sshUser)}`,
+    '[Unit]',
+    `Description=WildFly (${mode})${options.project ? ` for ${options.project}` : ''}`,
+    'After=network.target',
+    '',
+    '[Service]',
+    'Type=simple',
+    `User=${options.user || DEFAULT_SERVICE_USER}`,
+    `ExecStart=${wildflyRoot}/bin/${script} -b 0.0.0.0`,
+    `ExecStop=${wildflyRoot}/bin/jboss-cli.sh --connect --command=${shutdownCommand}`,
+    'Restart=on-failure',
+    'TimeoutStartSec=300',
+    '',
+    '[Install]',
+    'WantedBy=multi-user.target'
+  ].join('\n');
+}
+
+export {
+  createSystemdUnit,
+  getSystemdRestartCommand
+};
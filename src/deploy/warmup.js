@@ -0,0 +1,27 @@
+const DEFAULT_WARMUP_TIMEOUT = 10000;
+
+async function runWarmup(urls = [], options = {}) {
+  const fetchImpl = options.fetch || fetch;
+  const timeout = options.timeout || DEFAULT_WARMUP_TIMEOUT;
+  const results = [];
+
+  for (const url of urls) {
+    try {
+      const response = await fetchImpl(url, { signal: AbortSignal.timeout(timeout) });
+      await response.arrayBuffer();
+      results.push({ url, ok: response.ok, status: response.status });
+    } catch (error) {
+      results.push({ url, ok: false, error: error.message });
+    }
+  }
+
+  return {
+    results,
+    succeeded: results.filter((result) => result.ok).length,
+    total: results.length
+  };
+}
+
+export {
+  runWarmup
+};
@@ -1,13 +1,156 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import ms from 'ms';
+import { JmwError } from '../errors.js';
+import { formatCommand, formatDetail, printDebug } from '../output.js';
+import { password } from '../utils.js';
+import { getLogPatterns } from './server-log.js';
+import { DEFAULT_BACKUP_COUNT } from './backup.js';
+
+const DEFAULT_CONNECT_TIMEOUT = '10s';
+const DEFAULT_IN_FLIGHT_TIMEOUT = '30s';
+const DEFAULT_DEPLOY_TIMEOUT = '60s';
+const DEFAULT_CLI_TIMEOUT = '120s';
+const DEFAULT_WATCH_TIMEOUT = '30s';
+const DEFAULT_CONTROLLER_HOST = 'localhost';
+const DEFAULT_CONTROLLER_PORT = 9990;
+const MANAGEMENT_PASSWORD_ENV = 'JMW_MANAGEMENT_PASSWORD';
+const REDACTED = '****';
+
 function getWildflyConfig(projectConfig) {
+  const serverGroups = getServerGroups(projectConfig);
+
   return {
     root: projectConfig.wildfly_root,
     mode: projectConfig.wildfly_mode || 'standalone',
-    serverGroup: projectConfig.server_group
+    serverGroups,
+    // The comma-separated form jboss-cli's --server-groups takes.
+    serverGroup: serverGroups.join(',') || undefined,
+    requireCliVersion: projectConfig.require_cli_version,
+    restartCmd: projectConfig.restart_cmd,
+    logDir: projectConfig.log_dir,
+    checkRunning: projectConfig.check_running !== false,
+    managementUser: projectConfig.management_user,
+    managementPassword: projectConfig.management_password,
+    controller: {
+      host: projectConfig.controller_host ?? DEFAULT_CONTROLLER_HOST,
+      port: parsePort(projectConfig.controller_port ?? DEFAULT_CONTROLLER_PORT)
+    },
+    connectTimeout: parseDuration(projectConfig.connect_timeout ?? DEFAULT_CONNECT_TIMEOUT),
+    inFlightTimeout: parseDuration(projectConfig.in_flight_timeout ?? DEFAULT_IN_FLIGHT_TIMEOUT),
+    backupCount: projectConfig.backup_count ?? DEFAULT_BACKUP_COUNT,
+    preserveTimestamps: projectConfig.preserve_timestamps !== false,
+    deployTimeout: parseDuration(projectConfig.deploy_timeout ?? DEFAULT_DEPLOY_TIMEOUT),
+    cliTimeout: parseDuration(projectConfig.cli_timeout ?? DEFAULT_CLI_TIMEOUT),
+    watchTimeout: parseDuration(projectConfig.watch_timeout ?? DEFAULT_WATCH_TIMEOUT),
+    logPatterns: getLogPatterns(projectConfig)
   };
 }
 
-function createDeploymentPlan(artifactPath, detection) {
+// Version 1 files are migrated to server_groups when loaded, so the older
+// single server_group is never read here.
+function getServerGroups(projectConfig) {
+  return projectConfig.server_groups ? [...projectConfig.server_groups] : [];
+}
+
+function formatServerGroups(wildflyConfig) {
+  return formatDetail(wildflyConfig.serverGroups.length > 1 ? 'groups' : 'group', wildflyConfig.serverGroups.join(', '));
+}
+
+function parseDuration(value) {
+  const duration = typeof value === 'number' ? value : ms(String(value));
+
+  if (!Number.isFinite(duration) || duration <= 0) {
+    throw new Error(`Invalid duration: ${value}`);
+  }
+
+  return duration;
+}
+
+function parsePort(value) {
+  const port = Number(value);
+
+  if (!Number.isInteger(port) || port < 1 || port > 65535) {
+    throw new Error(`Invalid port: ${value}`);
+  }
+
+  return port;
+}
+
+// --controller accepts 'host' or 'host:port'; a missing port keeps the configured one.
+function parseController(value, fallback = { host: DEFAULT_CONTROLLER_HOST, port: DEFAULT_CONTROLLER_PORT }) {
+  const [host, port] = String(value).split(':');
+
+  if (!host) {
+    throw new Error(`Invalid controller: ${value}`);
+  }
+
+  return { host, port: port === undefined ? fallback.port : parsePort(port) };
+}
+
+function formatController(controller) {
+  return `${controller.host}:${controller.port}`;
+}
+
+// Arguments for every jboss-cli.sh call made against this server; pass
+// redact for anything that is printed instead of run.
+function getConnectArgs(wildflyConfig, { redact = false } = {}) {
+  const args = [
+    '--connect',
+    `--controller=${formatController(wildflyConfig.controller)}`,
+    `--timeout=${wildflyConfig.connectTimeout}`
+  ];
+
+  if (wildflyConfig.managementUser) {
+    args.push(`--user=${wildflyConfig.managementUser}`);
+    if (redact || wildflyConfig.managementPassword) {
+      args.push(`--password=${redact ? REDACTED : wildflyConfig.managementPassword}`);
+    }
+  }
+
+  return args;
+}
+
+// Quotes a value inside a jboss-cli command line, where spaces end an
+// argument and commas separate the commands of --commands.
+function cliQuote(value) {
+  const text = String(value);
+  if (/^[\w@%+=:./-]+$/.test(text)) {
+    return text;
+  }
+
+  return `"${text.replace(/[\\"]/g, '\\$&')}"`;
+}
+
+// Fills in a missing management_password from the environment or a prompt,
+// once, before the first jboss-cli.sh call that needs it.
+async function resolveManagementCredentials(wildflyConfig, env = process.env) {
+  if (!wildflyConfig.managementUser || wildflyConfig.managementPassword) {
+    return wildflyConfig;
+  }
+
+  try {
+    wildflyConfig.managementPassword = env[MANAGEMENT_PASSWORD_ENV]
+      || await password(`jmw: management password for ${wildflyConfig.managementUser}@${formatController(wildflyConfig.controller)}`);
+  } catch (error) {
+    error.message = `${error.message} Set management_password or ${MANAGEMENT_PASSWORD_ENV}.`;
+    throw error;
+  }
+
+  return wildflyConfig;
+}
+
+// execFileSync errors quote the full command line, password included.
+function redactCredentials(text, wildflyConfig) {
+  const secret = wildflyConfig.managementPassword;
+  return secret ? String(text).split(secret).join(REDACTED) : text;
+}
+
+function createDeploymentPlan(artifactPath, detection, deployOptions = {}) {
   const wildflyConfig = getWildflyConfig(detection.projectConfig);
+  if (deployOptions.controller) {
+    wildflyConfig.controller = parseController(deployOptions.controller, wildflyConfig.controller);
+  }
 
   return {
     project: detection.project,
@@ -15,11 +158,176 @@ function createDeploymentPlan(artifactPath, detection) {
     module: detection.module,
     artifactPath,
     wildflyConfig,
+    deployOptions,
     deploymentType: detection.module.isGlobalModule ? 'Global Module' : 'Normal Deployment'
   };
 }
 
+function assertWildflyRoot(wildflyConfig) {
+  if (!wildflyConfig.root) {
+    throw new Error('Missing wildfly_root in configuration');
+  }
+
+  if (!fs.existsSync(wildflyConfig.root) || !fs.statSync(wildflyConfig.root).isDirectory()) {
+    throw new JmwError(`WildFly root not found: ${wildflyConfig.root}`, {
+      code: 'WILDFLY_ROOT_NOT_FOUND',
+      phase: 'validate'
+    });
+  }
+}
+
+function getCliPath(wildflyConfig, platform = process.platform) {
+  return path.join(wildflyConfig.root, 'bin', platform === 'win32' ? 'jboss-cli.bat' : 'jboss-cli.sh');
+}
+
+function assertCliPath(wildflyConfig, platform = process.platform) {
+  const cliPath = getCliPath(wildflyConfig, platform);
+
+  if (!fs.existsSync(cliPath)) {
+    throw new JmwError(`${path.basename(cliPath)} not found: ${cliPath}. Check that wildfly_root points at the WildFly installation, not a parent directory`, {
+      code: 'CLI_NOT_FOUND',
+      phase: 'validate'
+    });
+  }
+
+  return cliPath;
+}
+
+// Node refuses to spawn .bat files directly, so they run through cmd.exe.
+function getCliInvocation(cliPath, args = []) {
+  return /\.bat$/i.test(cliPath)
+    ? { command: 'cmd.exe', args: ['/d', '/c', cliPath, ...args] }
+    : { command: cliPath, args };
+}
+
+function debugCliInvocation({ command, args }, wildflyConfig) {
+  printDebug(`exec ${redactCredentials(formatCommand(command, args), wildflyConfig)}`);
+}
+
+// execFileSync reports a killed-on-timeout child as ETIMEDOUT.
+function isCliTimeout(error) {
+  return error?.code === 'ETIMEDOUT' || error?.timedOut === true;
+}
+
+function createCliTimeoutError(step, timeout, fields = {}) {
+  return new JmwError(`jboss-cli ${step} timed out after ${ms(timeout)} and was killed`, {
+    code: 'CLI_TIMEOUT',
+    ...fields
+  });
+}
+
+function getDeploymentsDir(wildflyConfig) {
+  return path.join(wildflyConfig.root, 'standalone', 'deployments');
+}
+
+// server.log in log_dir (a custom jboss.server.log.dir) when set, else in
+// the conventional <root>/<mode>/log; remote roots pass path.posix.join.
+function getServerLogPath(root, mode, logDir, join = path.join) {
+  return logDir ? join(logDir, 'server.log') : join(root, mode, 'log', 'server.log');
+}
+
+// Domain servers each log under domain/servers/<server>/log, so without a
+// log_dir all of them are followed when they exist.
+function getLocalServerLogPaths(wildflyConfig) {
+  const serversDir = path.join(wildflyConfig.root, 'domain', 'servers');
+
+  if (wildflyConfig.mode === 'domain' && !wildflyConfig.logDir && fs.existsSync(serversDir)) {
+    const serverLogs = fs.readdirSync(serversDir)
+      .map((server) => path.join(serversDir, server, 'log', 'server.log'))
+      .filter((logPath) => fs.existsSync(logPath));
+
+    if (serverLogs.length > 0) {
+      return serverLogs;
+    }
+  }
+
+  return [getServerLogPath(wildflyConfig.root, wildflyConfig.mode, wildflyConfig.logDir)];
+}
+
+// A global module path must name a directory below <root>/modules; anything
+// else (a typo, `..`, an absolute path) would create directories elsewhere.
+function assertModulePath(deploymentPath) {
+  const normalized = path.posix.normalize(String(deploymentPath).replace(/\\/g, '/'));
+
+  if (path.posix.isAbsolute(normalized) || !normalized.startsWith('modules/') || normalized === 'modules/') {
+    throw new JmwError(`Global module path '${deploymentPath}' is not under the WildFly modules directory (expected modules/...)`, {
+      code: 'MODULE_PATH_INVALID',
+      phase: 'deploy'
+    });
+  }
+
+  return normalized;
+}
+
+function getLocalTargetPaths(artifactPath, wildflyConfig, moduleInfo, deployOptions = {}) {
+  const artifactName = path.basename(artifactPath);
+  const moduleTargets = moduleInfo.deploymentPaths.map((deploymentPath) => path.join(wildflyConfig.root, deploymentPath, artifactName));
+
+  if (moduleInfo.isGlobalModule && !deployOptions.alsoGlobal) {
+    return moduleTargets;
+  }
+
+  const deploymentTargets = wildflyConfig.mode === 'standalone'
+    ? [path.join(getDeploymentsDir(wildflyConfig), artifactName)]
+    : [];
+
+  return deployOptions.alsoGlobal ? [...deploymentTargets, ...moduleTargets] : deploymentTargets;
+}
+
+function getAccessUrl(artifactPath, projectConfig, host = 'localhost') {
+  if (path.extname(artifactPath).toLowerCase() !== '.war') {
+    return null;
+  }
+
+  const contextRoot = projectConfig.context_root ?? path.basename(artifactPath, path.extname(artifactPath));
+  const port = projectConfig.http_port || 8080;
+
+  return `http://${host}:${port}/${String(contextRoot).replace(/^\/+/, '')}`;
+}
+
+// A project's restart_cmd runs through the shell so wrapper scripts work;
+// without one the server is restarted through its management interface.
+function getRestartCommand(wildflyConfig) {
+  if (wildflyConfig.restartCmd) {
+    return { command: 'sh', args: ['-c', wildflyConfig.restartCmd], display: wildflyConfig.restartCmd };
+  }
+
+  const operation = wildflyConfig.mode === 'standalone' || wildflyConfig.serverGroups.length === 0
+    ? ':shutdown(restart=true)'
+    : wildflyConfig.serverGroups.map((group) => `/server-group=${group}:restart-servers`).join(',');
+  const cliPath = getCliPath(wildflyConfig);
+  const displayArgs = [...getConnectArgs(wildflyConfig, { redact: true }), `--commands=${operation}`];
+
+  return {
+    ...getCliInvocation(cliPath, [...getConnectArgs(wildflyConfig), `--commands=${operation}`]),
+    display: formatCommand(cliPath, displayArgs)
+  };
+}
+
 export {
   getWildflyConfig,
-  createDeploymentPlan
+  getServerGroups,
+  formatServerGroups,
+  parseDuration,
+  parseController,
+  formatController,
+  getConnectArgs,
+  cliQuote,
+  resolveManagementCredentials,
+  redactCredentials,
+  assertWildflyRoot,
+  getCliPath,
+  assertCliPath,
+  getCliInvocation,
+  debugCliInvocation,
+  isCliTimeout,
+  createCliTimeoutError,
+  getDeploymentsDir,
+  assertModulePath,
+  getServerLogPath,
+  getLocalServerLogPaths,
+  getLocalTargetPaths,
+  getAccessUrl,
+  createDeploymentPlan,
+  getRestartCommand
 };
@@ -1,9 +1,45 @@
-export { deployArtifact, getWildflyConfig, createDeploymentPlan, createRemoteDeploymentPlan, createDeployTarget } from './deploy/index.js';
-export { createDeploymentResult, executeDeploymentPlan, deployGlobalModule, deployNormal, deployStandalone, deployDomain } from './deploy/execution.js';
+export { deployArtifact, deployArtifactRemote, rollbackArtifact, undeployArtifact, createDeploymentReport, createRemoteDeploymentReport, getWildflyConfig, createDeploymentPlan, createRemoteDeploymentPlan, createDeployTarget } from './deploy/index.js';
+export { resolveRestartAction, restartWildfly } from './deploy/restart.js';
+export { runWarmup } from './deploy/warmup.js';
+export { parseHealthCheck, runHealthCheck } from './deploy/health-check.js';
+export { HOOK_STAGES, getDeployHooks, createHookEnv, runDeployHooks } from './deploy/hooks.js';
+export { parseNotification, renderNotifyTemplate, sendDeployNotification } from './deploy/notify.js';
+export { createSystemdUnit, getSystemdRestartCommand } from './deploy/systemd.js';
+export { readCliVersion, parseCliVersion, satisfiesVersion, assertCliVersion } from './deploy/cli-version.js';
+export { DEFAULT_LOG_PATTERNS, getLogPatterns, classifyLogLine } from './deploy/server-log.js';
+export { assertWildflyRoot, assertCliPath, getAccessUrl, getCliPath, getConnectArgs, getDeploymentsDir, getLocalServerLogPaths, getLocalTargetPaths, getServerLogPath, getRestartCommand, parseController, resolveManagementCredentials } from './deploy/wildfly.js';
+export { getRemoteTempDir, getRemoteTargetPaths, createRemoteChecks, runRemoteChecks, createRemoteSteps, createRemoteStandaloneSteps, createRemoteGlobalModuleSteps, createRemoteLogCommand, createRemoteRestartStep, createRemoteDomainSteps, executeRemoteSteps, withRemoteRunner } from './deploy/remote.js';
+export { createSftpRunner } from './deploy/sftp.js';
+export { getDefaultModuleName, renderModuleXml, syncModuleXml } from './deploy/module-xml.js';
+export { startLogWatch, followLogWatch, followServerLogs, followRemoteLog } from './deploy/log-watch.js';
+export { listProcesses, findWildflyProcesses, detectWildflyMode, checkWildflyMode, checkWildflyRunning } from './deploy/server-state.js';
+export { readStandaloneDeployments, readDomainDeployments, readArtifactServerGroups, parseDeploymentInfo, parseServerGroupStates } from './deploy/status.js';
+export { DEFAULT_BACKUP_COUNT, getBackupDir, listBackups, createBackup, pruneBackups, findLatestBackup } from './deploy/backup.js';
+export { createDeploymentResult, executeDeploymentPlan, deployGlobalModule, deployNormal, deployStandalone, deployDomain, undeployStandaloneArtifact, undeployDomainArtifact, splitArtifactName, findStaleDeployments } from './deploy/execution.js';
 export {
   showDeploymentPlan,
   showDeploymentSuccess,
   showDeploymentSummary,
+  showDryRunNotice,
   showDeploymentRestartGuidance,
-  showRemoteDeploymentGuide
+  showRestartAction,
+  showWarmupReport,
+  showHealthCheckReport,
+  showAccessUrl,
+  showRemoteDeploymentGuide,
+  showRemoteDeploymentPlan,
+  showRemoteCheckResults,
+  showRemoteExecutionSummary,
+  showDeployBatch,
+  showMultiDeploySummary,
+  showDeployResultJson,
+  showDeploymentStatus,
+  showDeployHistory,
+  showDoctorResults,
+  showConfigValidation,
+  showRollbackPlan,
+  showRollbackSuccess,
+  showUndeployPlan,
+  showUndeploySuccess
 } from './deploy/reporting.js';
+export { setOutputStreams } from './output.js';
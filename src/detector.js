@@ -1,7 +1,10 @@
 export {
   detectProject,
+  detectFromDir,
   findProjectConfig,
   findPomXml,
   parsePom,
-  detectModule
+  detectModule,
+  resolveFinalName
 } from './project/detector.js';
+export { readGitInfo } from './project/git.js';
@@ -0,0 +1,289 @@
+import fs from 'node:fs';
+import path from 'node:path';
+import { loadConfig, resolveConfigFiles } from './config.js';
+import { detectProject, parseGlobalModuleConfig } from './project/detector.js';
+import { assertModulePath, assertWildflyRoot, getCliPath, getDeploymentsDir, getWildflyConfig } from './deploy/wildfly.js';
+import { detectWildflyMode } from './deploy/server-state.js';
+import { parseHealthCheck } from './deploy/health-check.js';
+import { HOOK_STAGES, getDeployHooks } from './deploy/hooks.js';
+import { parseNotification } from './deploy/notify.js';
+import { parseStaleCheck } from './build/artifacts.js';
+
+// Hard checks fail `jmw doctor`; soft checks only warn.
+function runDoctorChecks(cwd = process.cwd(), env = process.env) {
+  const checks = [];
+  const record = (title, run, { soft = false } = {}) => {
+    try {
+      const detail = run();
+      checks.push({ title, passed: true, soft, detail });
+      return true;
+    } catch (error) {
+      checks.push({ title, passed: false, soft, detail: error.message, hint: error.hint });
+      return false;
+    }
+  };
+
+  let config;
+  let configFiles = [];
+  if (!record('configuration loads and restart rules compile', () => {
+    config = loadConfig(cwd, env);
+    configFiles = resolveConfigFiles(cwd, env);
+    return ['built-in', ...configFiles].join(' + ');
+  })) {
+    return checks;
+  }
+
+  let detection;
+  if (!record('current directory belongs to a configured project', () => {
+    detection = detectProject(config, cwd);
+    return `${detection.project} / ${detection.module.artifactId}`;
+  })) {
+    return checks;
+  }
+
+  const wildflyConfig = getWildflyConfig(detection.projectConfig);
+  const configFile = describeConfigFile(configFiles);
+  const rootExists = record('WildFly root exists', () => {
+    try {
+      assertWildflyRoot(wildflyConfig);
+    } catch (error) {
+      throw withHint(error, `set wildfly_root for ${detection.project} in ${configFile} (a relative path resolves against that file's directory)`);
+    }
+    return wildflyConfig.root;
+  });
+
+  if (rootExists) {
+    const cliPath = getCliPath(wildflyConfig);
+    record(`bin/${path.basename(cliPath)} is present`, () => {
+      if (!fs.existsSync(cliPath)) {
+        throw withHint(new Error(`not found: ${cliPath}`), 'check that wildfly_root points at the WildFly installation, not a parent directory');
+      }
+      return cliPath;
+    }, { soft: wildflyConfig.mode === 'standalone' });
+
+    const deployDir = wildflyConfig.mode === 'standalone'
+      ? getDeploymentsDir(wildflyConfig)
+      : path.join(wildflyConfig.root, 'domain');
+    record(`${wildflyConfig.mode === 'standalone' ? 'deployments' : 'domain'} directory is writable`, () => {
+      assertWritableDir(deployDir, configFile);
+      return deployDir;
+    });
+
+    record('wildfly_mode matches the installation', () => {
+      const detected = detectWildflyMode(wildflyConfig);
+      if (!detected) {
+        return `${wildflyConfig.mode} (no running server or boot log to compare)`;
+      }
+      if (detected.mode !== wildflyConfig.mode) {
+        throw withHint(new Error(`configured ${wildflyConfig.mode}, detected ${detected.mode} from ${detected.source}`), 'set wildfly_mode to the mode WildFly is started in');
+      }
+      return `${detected.mode} (${detected.source})`;
+    }, { soft: true });
+
+    if (wildflyConfig.mode === 'domain') {
+      record('server_groups is configured', () => {
+        if (wildflyConfig.serverGroups.length === 0) {
+          throw withHint(new Error('missing server_groups'), 'set server_groups for domain-mode projects');
+        }
+        return wildflyConfig.serverGroups.join(', ');
+      });
+    }
+  }
+
+  const clients = Object.entries(detection.projectConfig.clients || {});
+  if (clients.some(([, clientConfig]) => clientConfig.transport !== 'sftp')) {
+    for (const binary of ['ssh', 'scp']) {
+      record(`${binary} is on PATH for remote clients`, () => {
+        const binaryPath = findOnPath(binary, env);
+        if (!binaryPath) {
+          throw withHint(new Error(`${binary} not found on PATH`), "install OpenSSH or set transport: 'sftp' on the client");
+        }
+        return binaryPath;
+      }, { soft: true });
+    }
+  }
+
+  return checks;
+}
+
+const WILDFLY_MODES = ['standalone', 'domain'];
+const CLIENT_TRANSPORTS = ['ssh', 'sftp'];
+
+// Structural checks of every configured project, without touching WildFly or
+// the network, so a shared config can be checked before it is committed.
+// Loading already compiles the restart rules and fails on the first bad one.
+function runConfigValidation(cwd = process.cwd(), env = process.env) {
+  const config = loadConfig(cwd, env);
+
+  return {
+    files: resolveConfigFiles(cwd, env),
+    projects: Object.entries(config.projects || {})
+      .map(([projectName, projectConfig]) => validateProject(projectName, projectConfig))
+  };
+}
+
+function validateProject(projectName, projectConfig) {
+  const problems = [];
+  const report = (field, message) => problems.push(`projects.${projectName}.${field} ${message}`);
+  const mode = projectConfig.wildfly_mode || 'standalone';
+
+  if (!isNonEmptyString(projectConfig.base_path)) {
+    report('base_path', 'must be set');
+  }
+
+  if (!isNonEmptyString(projectConfig.wildfly_root)) {
+    report('wildfly_root', 'must be set');
+  }
+
+  if (!WILDFLY_MODES.includes(mode)) {
+    report('wildfly_mode', `'${mode}' is invalid. Expected one of: ${WILDFLY_MODES.join(', ')}`);
+  }
+
+  // Older files were migrated on load; a server_group left over comes from a
+  // version 2 file and would be ignored.
+  if (projectConfig.server_group !== undefined) {
+    report('server_group', 'was replaced by server_groups in config version 2');
+  }
+
+  let wildflyConfig = null;
+  try {
+    wildflyConfig = getWildflyConfig(projectConfig);
+  } catch (error) {
+    problems.push(`projects.${projectName}: ${error.message}`);
+  }
+
+  if (mode === 'domain' && wildflyConfig?.serverGroups.length === 0) {
+    report('server_groups', 'is required in domain mode');
+  }
+
+  try {
+    parseHealthCheck(projectConfig.health_check);
+  } catch (error) {
+    problems.push(`projects.${projectName}: ${error.message}`);
+  }
+
+  try {
+    parseNotification(projectConfig.notification);
+  } catch (error) {
+    problems.push(`projects.${projectName}: ${error.message}`);
+  }
+
+  try {
+    parseStaleCheck(projectConfig.stale_check);
+  } catch (error) {
+    problems.push(`projects.${projectName}: ${error.message}`);
+  }
+
+  for (const stage of Object.values(HOOK_STAGES)) {
+    try {
+      getDeployHooks(projectConfig, stage);
+    } catch (error) {
+      problems.push(`projects.${projectName}: ${error.message}`);
+    }
+  }
+
+  const globalModules = Object.entries(projectConfig.global_modules || {}).map(([artifactId, moduleConfig]) => {
+    const { deploymentPaths, moduleXml } = parseGlobalModuleConfig(moduleConfig);
+    if (deploymentPaths.length === 0 || deploymentPaths.some((deploymentPath) => !isNonEmptyString(deploymentPath) || path.isAbsolute(deploymentPath))) {
+      report(`global_modules.${artifactId}`, 'must be a path (or list of paths) relative to wildfly_root');
+    } else {
+      deploymentPaths.forEach((deploymentPath) => {
+        try {
+          assertModulePath(deploymentPath);
+        } catch (error) {
+          report(`global_modules.${artifactId}`, `'${deploymentPath}' is not under modules/`);
+        }
+      });
+    }
+    if (moduleXml && (!Array.isArray(moduleXml.dependencies) || moduleXml.dependencies.some((dependency) => !isNonEmptyString(dependency)))) {
+      report(`global_modules.${artifactId}.dependencies`, 'must be a list of module names');
+    }
+    return { artifactId, deploymentPaths };
+  });
+
+  const clients = Object.entries(projectConfig.clients || {});
+  for (const [clientName, clientConfig] of clients) {
+    const field = `clients.${clientName}`;
+    for (const key of ['host', 'user', 'wildfly_path']) {
+      if (!isNonEmptyString(clientConfig?.[key])) {
+        report(`${field}.${key}`, 'must be set');
+      }
+    }
+
+    for (const key of ['wildfly_path', 'log_dir']) {
+      if (isNonEmptyString(clientConfig?.[key]) && !clientConfig[key].startsWith('/')) {
+        report(`${field}.${key}`, `'${clientConfig[key]}' must be an absolute path on the client`);
+      }
+    }
+
+    if (clientConfig?.transport !== undefined && !CLIENT_TRANSPORTS.includes(clientConfig.transport)) {
+      report(`${field}.transport`, `'${clientConfig.transport}' is invalid. Expected one of: ${CLIENT_TRANSPORTS.join(', ')}`);
+    }
+
+    if (clientConfig?.host_fingerprint !== undefined && !/^SHA256:[A-Za-z0-9+/]{43}$/.test(clientConfig.host_fingerprint)) {
+      report(`${field}.host_fingerprint`, `'${clientConfig.host_fingerprint}' must be a SHA256 fingerprint as printed by ssh-keygen -l`);
+    }
+  }
+
+  return {
+    name: projectName,
+    mode,
+    wildflyConfig,
+    globalModules,
+    clients: clients.map(([clientName]) => clientName),
+    problems
+  };
+}
+
+// The highest-precedence file is where a setting is usually changed; with
+// only the built-in config, `jmw config show` tells where the values come from.
+function describeConfigFile(configFiles) {
+  return configFiles.length > 0 ? configFiles.at(-1) : 'a jmw.config.json (see `jmw config show`)';
+}
+
+function isNonEmptyString(value) {
+  return typeof value === 'string' && value.length > 0;
+}
+
+function assertWritableDir(dir, configFile) {
+  if (!fs.existsSync(dir) || !fs.statSync(dir).isDirectory()) {
+    throw withHint(new Error(`not found: ${dir}`), `check wildfly_root and wildfly_mode in ${configFile}`);
+  }
+
+  try {
+    fs.accessSync(dir, fs.constants.W_OK);
+  } catch {
+    throw withHint(new Error(`not writable: ${dir}`), 'run jmw as the user that owns the WildFly installation');
+  }
+}
+
+function findOnPath(binary, env = process.env) {
+  const extensions = process.platform === 'win32'
+    ? (env.PATHEXT || '.EXE;.CMD;.BAT').split(';')
+    : [''];
+
+  for (const dir of (env.PATH || '').split(path.delimiter).filter(Boolean)) {
+    for (const extension of extensions) {
+      const candidate = path.join(dir, `${binary}${extension}`);
+      try {
+        fs.accessSync(candidate, fs.constants.X_OK);
+        return candidate;
+      } catch {
+        // keep looking
+      }
+    }
+  }
+
+  return null;
+}
+
+function withHint(error, hint) {
+  error.hint = hint;
+  return error;
+}
+
+export {
+  runDoctorChecks,
+  runConfigValidation,
+  findOnPath
+};
@@ -0,0 +1,30 @@
+const EXIT_CODES = Object.freeze({
+  FAILURE: 1,
+  PARTIAL: 3
+});
+
+class JmwError extends Error {
+  constructor(message, { code = 'ERROR', phase = null, artifact = null, exitCode = EXIT_CODES.FAILURE } = {}) {
+    super(message);
+    this.name = 'JmwError';
+    this.code = code;
+    this.phase = phase;
+    this.artifact = artifact;
+    this.exitCode = exitCode;
+  }
+}
+
+function toErrorReport(error) {
+  return {
+    error: error.message,
+    code: error.code || 'ERROR',
+    artifact: error.artifact ?? null,
+    phase: error.phase ?? null
+  };
+}
+
+export {
+  EXIT_CODES,
+  JmwError,
+  toErrorReport
+};
@@ -0,0 +1,7 @@
+// Package entry point for library use: everything the jmw/config,
+// jmw/detector, jmw/builder and jmw/deployer subpaths export, without the
+// CLI (importing src/cli.js parses process.argv and runs a command).
+export * from './config.js';
+export * from './detector.js';
+export * from './builder.js';
+export * from './deployer.js';
@@ -8,9 +8,16 @@ import {
 import {
   showDeploymentPlan,
   showDeploymentSuccess,
+  showDryRunNotice,
   showDeploymentSummary,
   showDeploymentRestartGuidance,
-  showRemoteDeploymentGuide
+  showRestartAction,
+  showWarmupReport,
+  showHealthCheckReport,
+  showAccessUrl,
+  showRemoteDeploymentGuide,
+  showRemoteDeploymentPlan,
+  showRemoteExecutionSummary
 } from '../deploy/reporting.js';
 
 function createBuildLifecycleHandlers() {
@@ -47,11 +54,31 @@ function createDeployLifecycleHandlers() {
     },
     {
       stage: LIFECYCLE_STAGES.POST_DEPLOY,
-      run: ({ plan, result }) => {
-        showDeploymentSuccess();
-        showDeploymentSummary(result);
-        showDeploymentRestartGuidance(plan.wildflyConfig, plan.module);
+      run: ({ plan, result, restartDecision, restartAdvice, accessUrl }) => {
+        if (result.dryRun) {
+          showDryRunNotice();
+        } else {
+          showDeploymentSuccess();
+          showDeploymentSummary(result);
+          showAccessUrl(accessUrl);
+        }
+        if (restartAdvice !== false) {
+          showDeploymentRestartGuidance(plan.wildflyConfig, restartDecision);
+        }
       }
+    },
+    {
+      stage: LIFECYCLE_STAGES.WARMUP_COMPLETED,
+      run: ({ warmup }) => showWarmupReport(warmup)
+    },
+    {
+      stage: LIFECYCLE_STAGES.HEALTH_CHECK_COMPLETED,
+      run: ({ healthCheck }) => showHealthCheckReport(healthCheck)
+    },
+    {
+      stage: LIFECYCLE_STAGES.RESTART_DECIDED,
+      matches: ({ restartRequested }) => restartRequested,
+      run: ({ restartAction }) => showRestartAction(restartAction)
     }
   ];
 }
@@ -60,7 +87,21 @@ function createRemoteLifecycleHandlers() {
   return [
     {
       stage: LIFECYCLE_STAGES.REMOTE_COMMAND_GENERATED,
-      run: ({ remotePlan, target }) => showRemoteDeploymentGuide(remotePlan, target?.clientName)
+      run: ({ remotePlan, accessUrl, target }) => {
+        showRemoteDeploymentGuide(remotePlan, target?.clientName);
+        showAccessUrl(accessUrl);
+      }
+    },
+    {
+      stage: LIFECYCLE_STAGES.PRE_REMOTE_DEPLOY,
+      run: ({ plan, target }) => showRemoteDeploymentPlan(plan, target.clientName)
+    },
+    {
+      stage: LIFECYCLE_STAGES.POST_REMOTE_DEPLOY,
+      run: ({ execution, accessUrl, target }) => {
+        showRemoteExecutionSummary(execution, target.clientName);
+        showAccessUrl(accessUrl);
+      }
     }
   ];
 }
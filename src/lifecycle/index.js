@@ -10,8 +10,16 @@ const LIFECYCLE_STAGES = Object.freeze({
   RESTART_NOT_REQUIRED: 'restart-not-required',
   RESTART_UNKNOWN: 'restart-unknown',
   PRE_DEPLOY: 'pre-deploy',
+  COPY_STARTED: 'copy-started',
+  COPY_PROGRESS: 'copy-progress',
+  MARKER_CREATED: 'marker-created',
   POST_DEPLOY: 'post-deploy',
-  REMOTE_COMMAND_GENERATED: 'remote-command-generated'
+  WARMUP_COMPLETED: 'warmup-completed',
+  HEALTH_CHECK_COMPLETED: 'health-check-completed',
+  RESTART_DECIDED: 'restart-decided',
+  REMOTE_COMMAND_GENERATED: 'remote-command-generated',
+  PRE_REMOTE_DEPLOY: 'pre-remote-deploy',
+  POST_REMOTE_DEPLOY: 'post-remote-deploy'
 });
 
 function createLifecycle(handlers = []) {
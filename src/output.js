@@ -1,11 +1,184 @@
+import { AsyncLocalStorage } from 'node:async_hooks';
 import chalk from 'chalk';
 import logSymbols from 'log-symbols';
+import { shellQuote } from './utils.js';
 
-const PREFIX = chalk.cyan.bold('jmw ›');
-const DETAIL_SEPARATOR = chalk.dim(' · ');
+const LOG_FORMATS = ['text', 'json'];
+const OUTPUT_FORMATS = ['text', 'json'];
+const PLAIN_DETAIL_SEPARATOR = ' · ';
+const PLAIN_SYMBOLS = { success: '✔', warning: '⚠', error: '✖' };
+const ASCII_SYMBOLS = { success: '[OK]', warning: '[WARN]', error: '[FAIL]' };
+const ASCII_DETAIL_SEPARATOR = ' | ';
+// Non-ASCII characters used in messages, with the fallback for logs and CI.
+const GLYPHS = {
+  prompt: ['›', '>'],
+  arrow: ['→', '->'],
+  dash: ['—', '-'],
+  check: ['✓', 'ok']
+};
+const TABLE_TONES = {
+  success: (text) => chalk.green(text),
+  failure: (text) => chalk.red(text),
+  warning: (text) => chalk.yellow(text)
+};
+
+let logFormat = 'text';
+let outputFormat = 'text';
+let colorEnabled = chalk.level > 0;
+let verbose = false;
+let quiet = false;
+let streams = { stdout: process.stdout, stderr: process.stderr };
+
+function setColorEnabled(enabled, env = process.env) {
+  colorEnabled = enabled !== false && !env.NO_COLOR && chalk.level > 0;
+  if (!colorEnabled) {
+    chalk.level = 0;
+  }
+}
+
+// Lets library callers and tests capture output instead of the terminal.
+function setOutputStreams({ stdout = process.stdout, stderr = process.stderr } = {}) {
+  streams = { stdout, stderr };
+}
+
+const outputBuffers = new AsyncLocalStorage();
+
+function writeLine(line) {
+  emitLine('stdout', line);
+}
+
+function writeErrorLine(line) {
+  emitLine('stderr', line);
+}
+
+function emitLine(streamName, line) {
+  const buffer = outputBuffers.getStore();
+  if (buffer) {
+    buffer.push([streamName, line]);
+    return;
+  }
+
+  write(streams[streamName], line);
+}
+
+// Holds back everything printed while fn runs and writes it in one go when
+// it settles, so concurrent tasks print whole blocks instead of interleaving.
+async function withBufferedOutput(fn) {
+  const buffer = [];
+
+  try {
+    return await outputBuffers.run(buffer, fn);
+  } finally {
+    buffer.forEach(([streamName, line]) => write(streams[streamName], line));
+  }
+}
+
+const guardedStreams = new WeakSet();
+
+// Like console.log, stop quietly when the reader goes away (`jmw status | head`).
+function write(stream, line) {
+  if (!guardedStreams.has(stream) && typeof stream.on === 'function') {
+    guardedStreams.add(stream);
+    stream.on('error', (error) => {
+      if (error.code !== 'EPIPE') {
+        throw error;
+      }
+    });
+  }
+
+  stream.write(`${line}\n`);
+}
+
+// Redirected output (files, CI logs) often ends up in viewers that mangle
+// non-ASCII, so only a terminal gets symbols and box characters.
+function isAsciiOutput() {
+  return !streams.stdout.isTTY;
+}
+
+function glyph(name) {
+  const [unicode, ascii] = GLYPHS[name];
+  return isAsciiOutput() ? ascii : unicode;
+}
+
+function prefix() {
+  return chalk.cyan.bold(`jmw ${glyph('prompt')}`);
+}
+
+function detailSeparator() {
+  return chalk.dim(isAsciiOutput() ? ASCII_DETAIL_SEPARATOR : PLAIN_DETAIL_SEPARATOR);
+}
+
+function symbol(name) {
+  if (isAsciiOutput()) {
+    return ASCII_SYMBOLS[name];
+  }
+
+  return colorEnabled ? logSymbols[name] : PLAIN_SYMBOLS[name];
+}
+
+function setQuiet(enabled) {
+  quiet = enabled === true;
+}
+
+function setVerbose(enabled) {
+  verbose = enabled === true;
+}
+
+function isVerbose() {
+  return verbose;
+}
+
+function setLogFormat(format) {
+  if (!LOG_FORMATS.includes(format)) {
+    throw new Error(`Unsupported log format '${format}'. Expected one of: ${LOG_FORMATS.join(', ')}`);
+  }
+
+  logFormat = format;
+}
+
+function getLogFormat() {
+  return logFormat;
+}
+
+function setOutputFormat(format) {
+  if (!OUTPUT_FORMATS.includes(format)) {
+    throw new Error(`Unsupported output format '${format}'. Expected one of: ${OUTPUT_FORMATS.join(', ')}`);
+  }
+
+  outputFormat = format;
+}
+
+function getOutputFormat() {
+  return outputFormat;
+}
+
+// Structured output replaces the log lines with one result object.
+function isMuted() {
+  return outputFormat === 'json';
+}
+
+// --quiet keeps only the one-line outcomes (success, warning, error) and
+// result tables; JSON output is quieter still.
+function isQuiet() {
+  return quiet || isMuted();
+}
+
+// Child processes write to stderr while stdout is reserved for the result.
+function getCommandStdio() {
+  return isMuted() ? ['inherit', 2, 'inherit'] : 'inherit';
+}
+
+// The streams to copy piped child output to (jboss-cli, SFTP sessions), with
+// the same stdout-to-stderr rule as getCommandStdio.
+function getCommandStreams() {
+  return {
+    stdout: isMuted() ? streams.stderr : streams.stdout,
+    stderr: streams.stderr
+  };
+}
 
 function hasValue(value) {
-  return value !== undefined && value !== null && value !== '';
+  return value !== undefined && value !== null && String(value) !== '';
 }
 
 function formatDetail(label, value) {
@@ -13,68 +186,221 @@ function formatDetail(label, value) {
     return '';
   }
 
+  if (logFormat === 'json') {
+    return `${label} ${value}`;
+  }
+
   return `${chalk.bold(label)} ${value}`;
 }
 
-
 function formatCommand(command, args = []) {
-  return [command, ...args].filter(hasValue).join(' ');
+  return [command, ...args.filter(hasValue).map(shellQuote)].filter(hasValue).join(' ');
 }
 
 function joinDetails(details = []) {
-  return details.filter(hasValue).join(DETAIL_SEPARATOR);
+  const present = details.filter(hasValue);
+
+  return present.join(logFormat === 'json' ? PLAIN_DETAIL_SEPARATOR : detailSeparator());
 }
 
 function renderSection(title, details = []) {
   const suffix = joinDetails(details);
   const message = suffix
-    ? `${chalk.bold(title)}${DETAIL_SEPARATOR}${suffix}`
+    ? `${chalk.bold(title)}${detailSeparator()}${suffix}`
     : chalk.bold(title);
 
-  return `${PREFIX} ${message}`;
+  return `${prefix()} ${message}`;
 }
 
 function renderInfo(message) {
-  return `${PREFIX} ${message}`;
+  return `${prefix()} ${message}`;
+}
+
+function renderDebug(message) {
+  return `${prefix()} ${chalk.dim(`debug ${message}`)}`;
 }
 
 function renderSuccess(message) {
-  return `${PREFIX} ${logSymbols.success} ${chalk.green(message)}`;
+  return `${prefix()} ${symbol('success')} ${chalk.green(message)}`;
 }
 
 function renderWarning(message) {
-  return `${PREFIX} ${logSymbols.warning} ${chalk.yellow(message)}`;
+  return `${prefix()} ${symbol('warning')} ${chalk.yellow(message)}`;
 }
 
 function renderError(message) {
-  return `${PREFIX} ${logSymbols.error} ${chalk.red(message)}`;
+  return `${prefix()} ${symbol('error')} ${chalk.red(message)}`;
+}
+
+function renderTable(columns, rows, width = process.stdout.columns || 0) {
+  const cellText = (row, column) => (hasValue(row[column.key]) ? String(row[column.key]) : '');
+  const widths = columns.map((column) => Math.max(
+    column.label.length,
+    ...rows.map((row) => cellText(row, column).length)
+  ));
+
+  // Shrink the last column so rows do not wrap on narrow terminals.
+  if (width > 0) {
+    const fixed = widths.slice(0, -1).reduce((sum, columnWidth) => sum + columnWidth + 2, 0);
+    widths[widths.length - 1] = Math.max(8, Math.min(widths[widths.length - 1], width - fixed));
+  }
+
+  const renderRow = (cells) => cells
+    .map((cell, index) => fitCell(cell, widths[index]))
+    .join('  ')
+    .trimEnd();
+
+  return [
+    chalk.bold(renderRow(columns.map((column) => column.label))),
+    ...rows.map((row) => {
+      const line = renderRow(columns.map((column) => cellText(row, column)));
+      return TABLE_TONES[row.tone] ? TABLE_TONES[row.tone](line) : line;
+    })
+  ];
+}
+
+function fitCell(text, width) {
+  if (text.length > width) {
+    const ellipsis = isAsciiOutput() ? '...' : '…';
+    return `${text.slice(0, Math.max(0, width - ellipsis.length))}${ellipsis}`;
+  }
+
+  return text.padEnd(width);
+}
+
+// fields are the indexed keys of a JSON log line (phase, artifact, duration,
+// ...); callers pass them explicitly because the message is free text.
+function renderJson(level, message, fields = {}) {
+  return JSON.stringify({
+    time: new Date().toISOString(),
+    level,
+    msg: String(message),
+    ...pickFields(fields)
+  });
+}
+
+function pickFields(fields) {
+  return Object.fromEntries(Object.entries(fields).filter(([, value]) => hasValue(value)));
+}
+
+function printSection(title, details = [], fields = {}) {
+  if (isQuiet()) {
+    return;
+  }
+
+  if (logFormat === 'json') {
+    const suffix = joinDetails(details);
+    writeLine(renderJson('info', suffix ? `${title}${PLAIN_DETAIL_SEPARATOR}${suffix}` : title, { phase: title, ...fields }));
+    return;
+  }
+
+  writeLine(renderSection(title, details));
+}
+
+function printInfo(message, fields = {}) {
+  if (isQuiet()) {
+    return;
+  }
+
+  writeLine(logFormat === 'json' ? renderJson('info', message, fields) : renderInfo(message));
+}
+
+function printSuccess(message, fields = {}) {
+  if (isMuted()) {
+    return;
+  }
+
+  writeLine(logFormat === 'json' ? renderJson('info', message, { outcome: 'success', ...fields }) : renderSuccess(message));
+}
+
+// Under -o json a warning still matters, so it moves to stderr instead of
+// being dropped with the other log lines.
+function printWarning(message, fields = {}) {
+  const line = logFormat === 'json' ? renderJson('warn', message, fields) : renderWarning(message);
+
+  if (isMuted()) {
+    writeErrorLine(line);
+    return;
+  }
+
+  writeLine(line);
 }
 
-function printSection(title, details = []) {
-  console.log(renderSection(title, details));
+// Debug lines go to stderr so they never mix with a JSON result on stdout.
+function printDebug(message, fields = {}) {
+  if (!verbose) {
+    return;
+  }
+
+  writeErrorLine(logFormat === 'json' ? renderJson('debug', message, fields) : renderDebug(message));
 }
 
-function printInfo(message) {
-  console.log(renderInfo(message));
+function printError(message, fields = {}) {
+  writeErrorLine(logFormat === 'json' ? renderJson('error', message, fields) : renderError(message));
 }
 
-function printSuccess(message) {
-  console.log(renderSuccess(message));
+function printErrorReport(report) {
+  writeErrorLine(JSON.stringify(report));
 }
 
-function printWarning(message) {
-  console.log(renderWarning(message));
+function printPlain(message) {
+  writeLine(String(message));
 }
 
-function printError(message) {
-  console.error(renderError(message));
+// Tables are the result of query commands (status, clients), so --quiet
+// keeps them.
+function printTable(columns, rows) {
+  if (isMuted()) {
+    return;
+  }
+
+  if (logFormat === 'json') {
+    rows.forEach((row) => {
+      const fields = Object.fromEntries(columns
+        .filter((column) => hasValue(row[column.key]))
+        .map((column) => [column.key, row[column.key]]));
+      writeLine(renderJson('info', Object.values(fields).join(PLAIN_DETAIL_SEPARATOR), {
+        ...fields,
+        outcome: row.tone
+      }));
+    });
+    return;
+  }
+
+  const width = streams.stdout.columns ? streams.stdout.columns - 6 : 0;
+  renderTable(columns, rows, width).forEach((line) => writeLine(`      ${line}`));
 }
 
 function printCommand(command) {
-  console.log(`      ${command}`);
+  if (isQuiet()) {
+    return;
+  }
+
+  if (logFormat === 'json') {
+    writeLine(renderJson('info', command, { command }));
+    return;
+  }
+
+  writeLine(`      ${command}`);
 }
 
 export {
+  LOG_FORMATS,
+  OUTPUT_FORMATS,
+  setLogFormat,
+  getLogFormat,
+  setOutputFormat,
+  getOutputFormat,
+  getCommandStdio,
+  getCommandStreams,
+  setOutputStreams,
+  withBufferedOutput,
+  setColorEnabled,
+  setVerbose,
+  isVerbose,
+  setQuiet,
+  isQuiet,
+  glyph,
   formatCommand,
   formatDetail,
   joinDetails,
@@ -83,10 +409,15 @@ export {
   renderSuccess,
   renderWarning,
   renderError,
+  renderTable,
   printSection,
   printInfo,
   printSuccess,
   printWarning,
+  printDebug,
   printError,
+  printErrorReport,
+  printPlain,
+  printTable,
   printCommand
 };
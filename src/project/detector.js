@@ -2,12 +2,24 @@ import fs from 'node:fs';
 import path from 'node:path';
 import { XMLParser } from 'fast-xml-parser';
 import { findUpSync } from 'find-up';
+import { getArtifactExtension } from '../build/artifacts.js';
 
 const parser = new XMLParser({
   ignoreAttributes: false,
   attributeNamePrefix: ''
 });
 
+/**
+ * Detect the configured project and Maven module containing `cwd`.
+ *
+ * Returns `{ project, projectConfig, restartRules, auditLog, pomPath, module }`
+ * where `module` is `{ artifactId, packaging, version, finalName, artifactName,
+ * path, relativePath, isGlobalModule, deploymentPath, deploymentPaths,
+ * moduleXml, isReactorBuild }` and `artifactName` is the file the pom produces
+ * (`<finalName>.<jar|war|ear>`). These fields are a stable contract for external tooling;
+ * new fields may be added but existing ones are not renamed or removed.
+ * Throws when no project or pom.xml is found.
+ */
 function detectProject(config, cwd = process.cwd()) {
   const currentPath = path.resolve(cwd);
   const matchedProject = findProjectConfig(config, currentPath);
@@ -28,11 +40,25 @@ function detectProject(config, cwd = process.cwd()) {
     project: matchedProject.name,
     projectConfig: matchedProject.config,
     restartRules: config.restart_rules,
+    auditLog: config.audit_log,
     pomPath,
     module
   };
 }
 
+/**
+ * Same as detectProject, for an explicit directory instead of the process cwd.
+ */
+function detectFromDir(dir, config) {
+  const directory = path.resolve(dir);
+
+  if (!fs.existsSync(directory) || !fs.statSync(directory).isDirectory()) {
+    throw new Error(`Not a directory: ${directory}`);
+  }
+
+  return detectProject(config, directory);
+}
+
 function findProjectConfig(config, currentPath) {
   for (const [projectName, projectConfig] of Object.entries(config.projects)) {
     if (currentPath.startsWith(projectConfig.base_path)) {
@@ -59,6 +85,8 @@ function parsePom(pomPath) {
 function detectModule(pomPath, pom, projectConfig) {
   const artifactId = pom.project?.artifactId;
   const packaging = pom.project?.packaging || 'jar';
+  const version = pom.project?.version || pom.project?.parent?.version || '';
+  const finalName = resolveFinalName(pom.project?.build?.finalName, artifactId, version);
 
   if (!artifactId) {
     throw new Error('artifactId not found in pom.xml');
@@ -68,22 +96,84 @@ function detectModule(pomPath, pom, projectConfig) {
   const relativePath = path.relative(projectConfig.base_path, modulePath);
   const dirName = path.basename(modulePath);
   const moduleConfig = projectConfig.global_modules?.[artifactId] ?? projectConfig.global_modules?.[dirName];
+  const { deploymentPaths, moduleXml } = parseGlobalModuleConfig(moduleConfig);
+  if (moduleXml) {
+    moduleXml.owners = getModuleOwners(projectConfig.global_modules, deploymentPaths);
+  }
 
   return {
     artifactId,
     packaging,
+    version,
+    finalName,
+    artifactName: `${finalName}.${getArtifactExtension(packaging)}`,
     path: modulePath,
     relativePath,
     isGlobalModule: Boolean(moduleConfig),
-    deploymentPath: moduleConfig || '',
+    deploymentPath: deploymentPaths[0] || '',
+    deploymentPaths,
+    moduleXml,
     isReactorBuild: projectConfig.reactor_build === true
   };
 }
 
+// A global_modules entry is a path, a list of paths, or
+// `{ path, module, dependencies }`; only the object form has jmw maintain
+// the module.xml (`module` defaults to the name derived from the path).
+function parseGlobalModuleConfig(moduleConfig) {
+  if (!moduleConfig) {
+    return { deploymentPaths: [], moduleXml: null };
+  }
+
+  if (typeof moduleConfig !== 'object' || Array.isArray(moduleConfig)) {
+    return { deploymentPaths: [].concat(moduleConfig), moduleXml: null };
+  }
+
+  return {
+    deploymentPaths: [].concat(moduleConfig.path ?? []),
+    moduleXml: {
+      name: moduleConfig.module,
+      dependencies: moduleConfig.dependencies || []
+    }
+  };
+}
+
+// deploymentPath -> the global_modules keys that copy into it, so module.xml
+// lists only the JARs of configured entries.
+function getModuleOwners(globalModules = {}, deploymentPaths) {
+  const owners = Object.fromEntries(deploymentPaths.map((deploymentPath) => [deploymentPath, []]));
+
+  for (const [key, moduleConfig] of Object.entries(globalModules)) {
+    for (const deploymentPath of parseGlobalModuleConfig(moduleConfig).deploymentPaths) {
+      owners[deploymentPath]?.push(key);
+    }
+  }
+
+  return owners;
+}
+
+function resolveFinalName(finalName, artifactId, version) {
+  if (!finalName) {
+    return version ? `${artifactId}-${version}` : artifactId;
+  }
+
+  const properties = {
+    'project.artifactId': artifactId,
+    'project.version': version,
+    artifactId,
+    version
+  };
+
+  return String(finalName).replace(/\$\{([^}]+)\}/g, (placeholder, name) => properties[name] ?? placeholder);
+}
+
 export {
   detectProject,
+  detectFromDir,
   findProjectConfig,
   findPomXml,
   parsePom,
-  detectModule
+  detectModule,
+  parseGlobalModuleConfig,
+  resolveFinalName
 };
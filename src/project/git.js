@@ -0,0 +1,31 @@
+import simpleGit from 'simple-git';
+
+// Branch, short commit and dirty flag of the checkout holding `directory`, or
+// null outside a git repository. Untracked files do not make the tree dirty,
+// as with `git describe --dirty`.
+async function readGitInfo(directory, gitFactory = simpleGit) {
+  if (!directory) {
+    return null;
+  }
+
+  try {
+    const git = gitFactory(directory);
+    const [commit, branch, status] = await Promise.all([
+      git.revparse(['--short', 'HEAD']),
+      git.revparse(['--abbrev-ref', 'HEAD']),
+      git.status()
+    ]);
+
+    return {
+      branch: branch.trim(),
+      commit: commit.trim(),
+      dirty: status.files.length > status.not_added.length
+    };
+  } catch {
+    return null;
+  }
+}
+
+export {
+  readGitInfo
+};
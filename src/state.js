@@ -0,0 +1,39 @@
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { findAuditLogFiles } from './audit.js';
+
+function getStateDir(env = process.env) {
+  const base = env.XDG_STATE_HOME || path.join(os.homedir(), '.local', 'state');
+  return path.join(base, 'jmw');
+}
+
+// The audit log (`jmw history`) lives here by default but is a record, not
+// state: entries holding audit files are kept unless includeAudit is set.
+function listStateEntries(stateDir = getStateDir(), { auditLog = null, includeAudit = false } = {}) {
+  if (!fs.existsSync(stateDir)) {
+    return [];
+  }
+
+  const auditFiles = auditLog && !includeAudit ? findAuditLogFiles(auditLog) : [];
+
+  return fs.readdirSync(stateDir)
+    .map((entry) => path.join(stateDir, entry))
+    .filter((entry) => !auditFiles.some((file) => file === entry || file.startsWith(`${entry}${path.sep}`)));
+}
+
+function clearState(stateDir = getStateDir(), options = {}) {
+  const entries = listStateEntries(stateDir, options);
+
+  for (const entry of entries) {
+    fs.rmSync(entry, { recursive: true, force: true });
+  }
+
+  return entries;
+}
+
+export {
+  getStateDir,
+  listStateEntries,
+  clearState
+};
@@ -1,9 +1,30 @@
 import prompts from 'prompts';
+import { JmwError } from './errors.js';
+
+let assumeYes = false;
+
+/**
+ * Answer every confirmation with yes (--yes)
+ */
+export function setAssumeYes(value) {
+  assumeYes = value === true;
+}
 
 /**
- * Simple confirmation prompt
+ * Simple confirmation prompt; fails instead of prompting without a terminal
  */
-export async function confirm(message) {
+export async function confirm(message, stdin = process.stdin) {
+  if (assumeYes) {
+    return true;
+  }
+
+  if (!stdin.isTTY) {
+    throw new JmwError(`Confirmation required (${message.replace(/^jmw: /, '')}) but stdin is not a terminal. Pass --yes to confirm non-interactively.`, {
+      code: 'CONFIRMATION_REQUIRED',
+      phase: 'confirm'
+    });
+  }
+
   const response = await prompts({
     type: 'confirm',
     name: 'value',
@@ -12,3 +33,78 @@ export async function confirm(message) {
   });
   return response.value ?? false;
 }
+
+/**
+ * Choice prompt; --yes cannot pick for the user, so it fails without a terminal
+ */
+export async function select(message, choices, stdin = process.stdin) {
+  if (!stdin.isTTY) {
+    throw new JmwError(`A choice is required (${message.replace(/^jmw: /, '')}) but stdin is not a terminal. Candidates: ${choices.map((choice) => choice.title).join(', ')}`, {
+      code: 'CHOICE_REQUIRED',
+      phase: 'confirm'
+    });
+  }
+
+  const response = await prompts({
+    type: 'select',
+    name: 'value',
+    message,
+    choices
+  });
+  return response.value ?? null;
+}
+
+/**
+ * Hidden input prompt; fails instead of prompting without a terminal
+ */
+export async function password(message, stdin = process.stdin) {
+  if (!stdin.isTTY) {
+    throw new JmwError(`A password is required (${message.replace(/^jmw: /, '')}) but stdin is not a terminal.`, {
+      code: 'PASSWORD_REQUIRED',
+      phase: 'confirm'
+    });
+  }
+
+  const response = await prompts({
+    type: 'password',
+    name: 'value',
+    message
+  });
+  return response.value ?? '';
+}
+
+/**
+ * Maps items with at most `limit` callbacks running at once; results keep the input order
+ */
+export async function mapConcurrent(items, limit, callback) {
+  const results = new Array(items.length);
+  let next = 0;
+  const worker = async () => {
+    while (next < items.length) {
+      const index = next++;
+      results[index] = await callback(items[index], index);
+    }
+  };
+
+  await Promise.all(Array.from({ length: Math.min(limit, items.length) }, worker));
+  return results;
+}
+
+/**
+ * Quote a value for a POSIX shell; safe values are returned unchanged
+ */
+export function shellQuote(value) {
+  const text = String(value);
+  if (/^[\w@%+=:,./-]+$/.test(text)) {
+    return text;
+  }
+
+  return `'${text.replace(/'/g, `'\\''`)}'`;
+}
+
+/**
+ * Wrap a remote command in double quotes for display as a single ssh argument
+ */
+export function quoteRemoteCommand(command) {
+  return `"${command.replace(/(["\\$`])/g, '\\$1')}"`;
+}
@@ -0,0 +1,64 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { createBackup, findLatestBackup, getBackupDir, listBackups } from '../src/deploy/backup.js';
+import { createTempDir } from './helpers.js';
+
+function writeTarget(targetPath, content) {
+  fs.mkdirSync(path.dirname(targetPath), { recursive: true });
+  fs.writeFileSync(targetPath, content);
+}
+
+test('backups in the same second, or millisecond, do not overwrite each other', (t) => {
+  const root = createTempDir(t, 'backup');
+  const targetPath = path.join(root, 'standalone', 'deployments', 'app.war');
+  const date = new Date(2026, 9, 16, 10, 30, 15, 250);
+
+  writeTarget(targetPath, 'v1');
+  const first = createBackup(targetPath, 5, { date, wildflyRoot: root });
+  writeTarget(targetPath, 'v2');
+  const second = createBackup(targetPath, 5, { date: new Date(2026, 9, 16, 10, 30, 15, 900), wildflyRoot: root });
+  writeTarget(targetPath, 'v3');
+  const third = createBackup(targetPath, 5, { date: new Date(2026, 9, 16, 10, 30, 15, 900), wildflyRoot: root });
+
+  assert.equal(path.basename(first), 'app.war.bak.20261016-103015-250');
+  assert.equal(path.basename(second), 'app.war.bak.20261016-103015-900');
+  assert.equal(path.basename(third), 'app.war.bak.20261016-103015-900-1');
+  assert.deepEqual(listBackups(targetPath, root).map((backupPath) => fs.readFileSync(backupPath, 'utf8')), ['v1', 'v2', 'v3']);
+  assert.equal(findLatestBackup(targetPath, root), third);
+});
+
+test('deployment backups stay next to the deployments directory', (t) => {
+  const root = createTempDir(t, 'backup');
+  const targetPath = path.join(root, 'standalone', 'deployments', 'app.war');
+
+  assert.equal(getBackupDir(targetPath, root), path.join(root, 'standalone', '.jmw-backups'));
+});
+
+test('global module backups are kept outside the modules tree', (t) => {
+  const root = createTempDir(t, 'backup');
+  const targetPath = path.join(root, 'modules', 'com', 'acme', 'core', 'main', 'core.jar');
+  writeTarget(targetPath, 'v1');
+
+  const backupPath = createBackup(targetPath, 5, { wildflyRoot: root });
+
+  assert.ok(backupPath.startsWith(path.join(root, '.jmw-backups', 'modules', 'com', 'acme', 'core', 'main') + path.sep));
+  assert.deepEqual(fs.readdirSync(path.join(root, 'modules', 'com', 'acme', 'core')), ['main']);
+  assert.equal(findLatestBackup(targetPath, root), backupPath);
+});
+
+test('only the newest backups are kept', (t) => {
+  const root = createTempDir(t, 'backup');
+  const targetPath = path.join(root, 'standalone', 'deployments', 'app.war');
+  writeTarget(targetPath, 'app');
+
+  for (let millisecond = 0; millisecond < 4; millisecond += 1) {
+    createBackup(targetPath, 2, { date: new Date(2026, 9, 16, 10, 30, 15, millisecond), wildflyRoot: root });
+  }
+
+  assert.deepEqual(listBackups(targetPath, root).map((backupPath) => path.basename(backupPath)), [
+    'app.war.bak.20261016-103015-002',
+    'app.war.bak.20261016-103015-003'
+  ]);
+});
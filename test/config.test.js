@@ -0,0 +1,127 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { config, createConfigNotFoundError, describeConfigLayers, loadConfig, migrateConfig } from '../src/config.js';
+import { getServerGroups } from '../src/deploy/wildfly.js';
+import { createTempDir, silenceOutput } from './helpers.js';
+
+function createConfigDir(t) {
+  silenceOutput(t);
+  return createTempDir(t, 'config');
+}
+
+function writeConfig(directory, content, name = 'jmw.config.json') {
+  const filePath = path.join(directory, name);
+  fs.writeFileSync(filePath, JSON.stringify(content));
+  return filePath;
+}
+
+function load(directory, env = {}) {
+  return loadConfig(directory, { XDG_CONFIG_HOME: path.join(directory, 'xdg'), ...env });
+}
+
+test('the built-in config is already at the current version', () => {
+  const { changes } = migrateConfig(config);
+
+  assert.deepEqual(changes, []);
+  assert.ok(Object.values(config.projects).every((projectConfig) => projectConfig.server_group === undefined));
+});
+
+test('a version 1 server_group is loaded as server_groups', (t) => {
+  const directory = createConfigDir(t);
+  writeConfig(directory, {
+    projects: { shop: { base_path: directory, wildfly_root: '/opt/wildfly', wildfly_mode: 'domain', server_group: 'main-server-group' } }
+  });
+
+  const shop = load(directory).projects.shop;
+
+  assert.equal(shop.server_group, undefined);
+  assert.deepEqual(getServerGroups(shop), ['main-server-group']);
+});
+
+test('getServerGroups only reads server_groups', () => {
+  assert.deepEqual(getServerGroups({ server_groups: ['a', 'b'] }), ['a', 'b']);
+  assert.deepEqual(getServerGroups({ server_group: 'a' }), []);
+});
+
+test('passwords and key paths keep a literal $', (t) => {
+  const directory = createConfigDir(t);
+  writeConfig(directory, {
+    version: 2,
+    projects: {
+      shop: {
+        base_path: '${JMW_TEST_BASE}',
+        wildfly_root: '/opt/wildfly',
+        management_password: 'pa$word',
+        clients: { test: { host: 'wildfly-1', user: 'deploy', wildfly_path: '/opt/wildfly', private_key: '/keys/$ci/id_ed25519', password: '$ecret' } }
+      }
+    }
+  });
+
+  const shop = load(directory, { JMW_TEST_BASE: directory }).projects.shop;
+
+  assert.equal(shop.base_path, directory);
+  assert.equal(shop.management_password, 'pa$word');
+  assert.equal(shop.clients.test.private_key, '/keys/$ci/id_ed25519');
+  assert.equal(shop.clients.test.password, '$ecret');
+});
+
+test('a relative wildfly_root resolves against the config file that sets it', (t) => {
+  const directory = createConfigDir(t);
+  writeConfig(directory, {
+    version: 2,
+    projects: { shop: { wildfly_root: '../servers/wildfly', log_dir: 'custom-log' } }
+  });
+
+  const shop = load(directory).projects.shop;
+
+  assert.equal(shop.wildfly_root, path.resolve(directory, '..', 'servers', 'wildfly'));
+  assert.equal(shop.log_dir, path.resolve(directory, '..', 'servers', 'wildfly', 'custom-log'));
+});
+
+test('the config layers are described with the winning one first', () => {
+  assert.equal(describeConfigLayers(null, null), 'built-in');
+  assert.equal(
+    describeConfigLayers('/home/me/.config/jmw/config.json', '/work/shop/jmw.config.json'),
+    'project /work/shop/jmw.config.json > user /home/me/.config/jmw/config.json > built-in'
+  );
+});
+
+test('the not-found error lists every searched path and the precedence', () => {
+  const cwd = path.join(path.sep, 'work', 'shop', 'web');
+  const error = createConfigNotFoundError(cwd, { XDG_CONFIG_HOME: '/xdg' });
+
+  assert.equal(error.code, 'CONFIG_NOT_FOUND');
+  for (const searched of [
+    path.join(cwd, 'jmw.config.json'),
+    path.join(path.sep, 'work', 'shop', 'jmw.config.json'),
+    path.join(path.sep, 'work', 'jmw.config.json'),
+    path.join(path.sep, 'jmw.config.json'),
+    path.join('/xdg', 'jmw', 'config.json'),
+    path.join(os.homedir(), '.config', 'jmw', 'config.json')
+  ]) {
+    assert.ok(error.message.split('\n').includes(`  ${searched}`), searched);
+  }
+  assert.match(error.message, /Precedence: --config, then JMW_CONFIG/);
+});
+
+test('restart rules may be globs; only a pattern that is neither fails to load', (t) => {
+  const directory = createConfigDir(t);
+  const rules = (patterns) => ({ version: 2, restart_rules: { patterns } });
+
+  writeConfig(directory, rules([
+    { match: '**/persistence.xml', reason: 'Persistence unit', severity: 'required' },
+    { match: 'src/**/*.java', reason: 'Java change', severity: 'recommended' },
+    { match: 'entities/.*\\.java', reason: 'Entity change', severity: 'required' }
+  ]));
+  assert.equal(load(directory).restart_rules.patterns.length, 3);
+
+  writeConfig(directory, rules([
+    { match: '*.xml', reason: 'XML change', severity: 'none' },
+    { match: '', reason: 'Broken', severity: 'required' }
+  ]));
+  assert.throws(() => load(directory), (error) => error.code === 'CONFIG_INVALID'
+    && /restart_rules\.patterns\[1\]\.match '' is neither a glob nor a regular expression/.test(error.message));
+});
@@ -0,0 +1,43 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { createHash, randomBytes } from 'node:crypto';
+import fs from 'node:fs';
+import path from 'node:path';
+import { copyArtifact, hashFile } from '../src/deploy/copy.js';
+import { createTempDir } from './helpers.js';
+
+const ARTIFACT_SIZE = 8 * 1024 * 1024 + 123;
+
+test('copies a multi-megabyte artifact in chunks and verifies its checksum', async (t) => {
+  const directory = createTempDir(t, 'copy');
+  const source = path.join(directory, 'app.war');
+  const dest = path.join(directory, 'deployments-app.war');
+  const content = randomBytes(ARTIFACT_SIZE);
+  fs.writeFileSync(source, content);
+  fs.utimesSync(source, new Date('2026-01-01T00:00:00Z'), new Date('2026-01-02T00:00:00Z'));
+
+  const progress = [];
+  const result = await copyArtifact(source, dest, (update) => progress.push(update));
+  const expected = createHash('sha256').update(content).digest('hex');
+
+  assert.equal(result.checksum, expected);
+  assert.equal(result.copiedBytes, ARTIFACT_SIZE);
+  assert.equal(result.totalBytes, ARTIFACT_SIZE);
+  assert.equal(await hashFile(dest), expected);
+  assert.ok(progress.length > 1, 'progress is reported per chunk');
+  assert.equal(progress.at(-1).copiedBytes, ARTIFACT_SIZE);
+  assert.equal(fs.statSync(dest).mtime.toISOString(), '2026-01-02T00:00:00.000Z');
+  assert.equal(fs.statSync(dest).mode & 0o644, 0o644);
+});
+
+test('preserveTimestamps: false leaves the copy with a fresh mtime', async (t) => {
+  const directory = createTempDir(t, 'copy');
+  const source = path.join(directory, 'app.jar');
+  const dest = path.join(directory, 'copy.jar');
+  fs.writeFileSync(source, randomBytes(1024));
+  fs.utimesSync(source, new Date('2026-01-01T00:00:00Z'), new Date('2026-01-02T00:00:00Z'));
+
+  await copyArtifact(source, dest, undefined, { preserveTimestamps: false });
+
+  assert.notEqual(fs.statSync(dest).mtime.toISOString(), '2026-01-02T00:00:00.000Z');
+});
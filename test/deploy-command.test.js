@@ -0,0 +1,55 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { getDeployExitCode, resolveArtifactPaths } from '../src/commands/deploy.js';
+import { EXIT_CODES } from '../src/errors.js';
+import { createTempDir } from './helpers.js';
+
+function createTarget(t, names) {
+  const directory = createTempDir(t, 'deploy-command');
+
+  names.forEach((name) => fs.writeFileSync(path.join(directory, name), ''));
+  return directory;
+}
+
+test('existing paths with glob characters are taken literally', (t) => {
+  const cwd = createTarget(t, ['app[1].war', 'report (final).war', 'api{v2}.war', 'app1.war']);
+
+  const resolution = resolveArtifactPaths(['app[1].war', 'report (final).war', 'api{v2}.war'], cwd);
+
+  assert.deepEqual(resolution.artifactPaths, [
+    path.join(cwd, 'api{v2}.war'),
+    path.join(cwd, 'app[1].war'),
+    path.join(cwd, 'report (final).war')
+  ]);
+  assert.deepEqual(resolution.unmatched, []);
+});
+
+test('patterns that match nothing are reported as unmatched', (t) => {
+  const cwd = createTarget(t, ['app.war']);
+
+  const resolution = resolveArtifactPaths(['app.war', 'missing-*.war'], cwd);
+
+  assert.deepEqual(resolution.artifactPaths, [path.join(cwd, 'app.war')]);
+  assert.deepEqual(resolution.unmatched, ['missing-*.war']);
+});
+
+test('exit codes', async (t) => {
+  const ok = { artifactPath: 'a.war', result: {} };
+  const failed = { artifactPath: 'b.war', error: new Error('boom') };
+  const cases = [
+    { name: 'all succeeded', entries: [ok, ok], unmatched: [], expected: undefined },
+    { name: 'all failed', entries: [failed, failed], unmatched: [], expected: EXIT_CODES.FAILURE },
+    { name: 'some failed', entries: [ok, failed], unmatched: [], expected: EXIT_CODES.PARTIAL },
+    { name: 'a pattern matched nothing', entries: [ok], unmatched: ['missing-*.war'], expected: EXIT_CODES.PARTIAL }
+  ];
+
+  for (const { name, entries, unmatched, expected } of cases) {
+    await t.test(name, () => {
+      assert.equal(getDeployExitCode(entries, unmatched), expected);
+    });
+  }
+
+  assert.equal(EXIT_CODES.PARTIAL, 3);
+});
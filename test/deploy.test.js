@@ -0,0 +1,112 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { deployArtifact, preflightParallelDeploy } from '../src/deploy/index.js';
+import { setAssumeYes } from '../src/utils.js';
+import { createTempDir, silenceOutput } from './helpers.js';
+
+function createWildflyRoot(t) {
+  const root = createTempDir(t, 'deploy');
+  silenceOutput(t);
+
+  fs.mkdirSync(path.join(root, 'standalone', 'deployments'), { recursive: true });
+  return root;
+}
+
+function createDetection(root, projectConfig = {}, module = {}) {
+  return {
+    project: 'shop',
+    projectConfig: { wildfly_root: root, wildfly_mode: 'standalone', check_running: false, deploy_timeout: '5s', ...projectConfig },
+    module: { artifactId: 'web', isGlobalModule: false, deploymentPaths: [], ...module },
+    auditLog: path.join(root, 'audit', 'deploy.log')
+  };
+}
+
+function writeArtifact(root, name) {
+  const artifactPath = path.join(root, 'target', name);
+  fs.mkdirSync(path.dirname(artifactPath), { recursive: true });
+  fs.writeFileSync(artifactPath, name);
+  return artifactPath;
+}
+
+// Stands in for the deployment scanner: every .dodeploy becomes .deployed.
+function runScanner(t, root) {
+  const deploymentsDir = path.join(root, 'standalone', 'deployments');
+  const scanner = setInterval(() => {
+    fs.readdirSync(deploymentsDir).filter((name) => name.endsWith('.dodeploy')).forEach((name) => {
+      fs.rmSync(path.join(deploymentsDir, name));
+      fs.writeFileSync(path.join(deploymentsDir, name.replace(/\.dodeploy$/, '.deployed')), '');
+    });
+  }, 20);
+  t.after(() => clearInterval(scanner));
+}
+
+function createFetch(healthStatus) {
+  const requested = [];
+  const fetch = async (url) => {
+    requested.push(url);
+    const status = url.endsWith('/health') ? healthStatus : 200;
+    return { status, ok: status < 400, arrayBuffer: async () => new ArrayBuffer(0) };
+  };
+
+  return { fetch, requested };
+}
+
+test('a parallel deploy settles stale versions before the workers start', async (t) => {
+  const root = createWildflyRoot(t);
+  const deploymentsDir = path.join(root, 'standalone', 'deployments');
+  ['web-0.9.war', 'web-1.0.war'].forEach((name) => fs.writeFileSync(path.join(deploymentsDir, name), ''));
+  setAssumeYes(true);
+  t.after(() => setAssumeYes(false));
+
+  const preflight = await preflightParallelDeploy(['/work/web-1.0.war', '/work/web-1.1.war'], createDetection(root));
+
+  assert.deepEqual(preflight, {
+    artifactPaths: ['/work/web-1.0.war', '/work/web-1.1.war'],
+    staleUndeploys: { 'web-1.0.war': ['web-0.9.war'], 'web-1.1.war': [] }
+  });
+});
+
+test('a dry run preflight asks nothing', async (t) => {
+  const root = createWildflyRoot(t);
+  fs.writeFileSync(path.join(root, 'standalone', 'deployments', 'web-0.9.war'), '');
+
+  const preflight = await preflightParallelDeploy(['/work/web-1.0.war'], createDetection(root), { dryRun: true });
+
+  assert.deepEqual(preflight, { artifactPaths: ['/work/web-1.0.war'], staleUndeploys: {} });
+});
+
+test('warmup runs only after the health check passes', async (t) => {
+  for (const [healthStatus, expected] of [
+    [200, ['http://shop/health', 'http://shop/warm']],
+    [503, ['http://shop/health']]
+  ]) {
+    await t.test(`health check returns ${healthStatus}`, async (t) => {
+      const root = createWildflyRoot(t);
+      runScanner(t, root);
+      const { fetch, requested } = createFetch(healthStatus);
+      const detection = createDetection(root, {
+        health_check: { url: 'http://shop/health', retries: 0 },
+        warmup_urls: ['http://shop/warm']
+      });
+
+      const result = await deployArtifact(writeArtifact(root, 'web-1.0.war'), detection, { confirmed: true, fetch });
+
+      assert.equal(result.healthCheck.healthy, healthStatus === 200);
+      assert.deepEqual(requested, expected);
+    });
+  }
+});
+
+test('a failed dry run leaves no audit entry', async (t) => {
+  const root = createWildflyRoot(t);
+  const detection = createDetection(root, {}, { isGlobalModule: true, deploymentPaths: ['standalone/lib'] });
+  const artifactPath = writeArtifact(root, 'web-1.0.jar');
+
+  await assert.rejects(deployArtifact(artifactPath, detection, { dryRun: true }), /not under the WildFly modules directory/);
+  assert.equal(fs.existsSync(detection.auditLog), false);
+
+  await assert.rejects(deployArtifact(artifactPath, detection, { confirmed: true }), /not under the WildFly modules directory/);
+  assert.equal(JSON.parse(fs.readFileSync(detection.auditLog, 'utf8')).outcome, 'failed');
+});
@@ -0,0 +1,77 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import path from 'node:path';
+import { createDeploymentReport, createRemoteDeploymentReport } from '../src/deploy/index.js';
+import { getWildflyConfig } from '../src/deploy/wildfly.js';
+
+const wildflyConfig = getWildflyConfig({ wildfly_root: '/opt/wildfly' });
+const restartDecision = { status: 'not-required', reason: 'WAR hot-deployment' };
+
+function createPlan(module, artifactPath = '/work/shop/web/target/web-1.0.war') {
+  return {
+    project: 'shop',
+    artifactPath,
+    wildflyConfig,
+    module,
+    deployOptions: {}
+  };
+}
+
+const webModule = { artifactId: 'web', isGlobalModule: false, deploymentPaths: [] };
+const coreModule = { artifactId: 'core', isGlobalModule: true, deploymentPaths: ['modules/com/acme/core/main'] };
+
+test('a standalone deploy reports its target, restart and duration', () => {
+  const startTime = new Date('2026-10-16T10:00:00Z');
+  const result = { startTime, endTime: new Date('2026-10-16T10:00:02.500Z'), backup: '/opt/wildfly/standalone/.jmw-backups/web-1.0.war.bak' };
+
+  assert.deepEqual(createDeploymentReport(createPlan(webModule), result, restartDecision, true), {
+    project: 'shop',
+    module: 'web',
+    artifact: 'web-1.0.war',
+    targetPaths: [path.join('/opt/wildfly', 'standalone', 'deployments', 'web-1.0.war')],
+    isGlobal: false,
+    restartSeverity: 'not-required',
+    restartReason: 'WAR hot-deployment',
+    duration: 2500,
+    succeeded: true,
+    dryRun: false,
+    backup: result.backup
+  });
+});
+
+test('a failed global module deploy reports the module paths', () => {
+  const report = createDeploymentReport(
+    createPlan(coreModule, '/work/shop/core/target/core-1.0.jar'),
+    { startTime: new Date(), dryRun: true },
+    { status: 'required', reason: 'Global module deployment' },
+    false
+  );
+
+  assert.deepEqual(report.targetPaths, [path.join('/opt/wildfly', 'modules/com/acme/core/main', 'core-1.0.jar')]);
+  assert.equal(report.isGlobal, true);
+  assert.equal(report.restartSeverity, 'required');
+  assert.equal(report.succeeded, false);
+  assert.equal(report.dryRun, true);
+  assert.equal(report.backup, null);
+  assert.ok(report.duration >= 0);
+});
+
+test('a remote deploy reports the client and its paths', () => {
+  const report = createRemoteDeploymentReport(
+    createPlan(webModule),
+    { clientName: 'trieste', clientConfig: { host: 'wildfly-1', user: 'deploy', wildfly_path: '/srv/wildfly' } },
+    { restartDecision, succeeded: true }
+  );
+
+  assert.deepEqual(report, {
+    project: 'shop',
+    module: 'web',
+    artifact: 'web-1.0.war',
+    client: 'trieste',
+    targetPaths: ['/srv/wildfly/standalone/deployments/web-1.0.war'],
+    isGlobal: false,
+    restartSeverity: 'not-required',
+    restartReason: 'WAR hot-deployment',
+    succeeded: true
+  });
+});
@@ -0,0 +1,24 @@
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { Writable } from 'node:stream';
+import { setOutputStreams } from '../src/output.js';
+
+// A fresh directory under the system temp dir, removed when the test ends.
+function createTempDir(t, name) {
+  const directory = fs.mkdtempSync(path.join(os.tmpdir(), `jmw-${name}-`));
+  t.after(() => fs.rmSync(directory, { recursive: true, force: true }));
+  return directory;
+}
+
+// Drops everything jmw prints until the test ends.
+function silenceOutput(t) {
+  const discard = new Writable({ write: (chunk, encoding, callback) => callback() });
+  setOutputStreams({ stdout: discard, stderr: discard });
+  t.after(() => setOutputStreams());
+}
+
+export {
+  createTempDir,
+  silenceOutput
+};
@@ -0,0 +1,27 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { createRemoteDomainSteps } from '../src/deploy/remote.js';
+import { cliQuote } from '../src/deploy/wildfly.js';
+
+test('plain paths and names are passed unquoted', () => {
+  assert.equal(cliQuote('/opt/build/target/app-1.0.war'), '/opt/build/target/app-1.0.war');
+});
+
+test('spaces, commas and quotes are quoted for jboss-cli', () => {
+  assert.equal(cliQuote('/home/me/My Projects/app.war'), '"/home/me/My Projects/app.war"');
+  assert.equal(cliQuote('/tmp/a,b.war'), '"/tmp/a,b.war"');
+  assert.equal(cliQuote('C:\\builds\\"odd".war'), '"C:\\\\builds\\\\\\"odd\\".war"');
+});
+
+test('remote domain deploy quotes an artifact path with spaces', () => {
+  const steps = createRemoteDomainSteps(
+    '/home/me/My Projects/target/my app.war',
+    { mode: 'domain', serverGroup: 'main-server-group' },
+    { host: 'wildfly-1', user: 'root', wildfly_path: '/opt/wildfly' }
+  );
+  const deployStep = steps.find((step) => step.title === 'Deploy to server group');
+  const remoteCommand = deployStep.args[1];
+
+  assert.match(remoteCommand, /deploy "\/tmp\/my app\.war" --name="my app\.war" --runtime-name="my app\.war" --server-groups=main-server-group/);
+  assert.equal(steps[0].args[0], '/home/me/My Projects/target/my app.war');
+});
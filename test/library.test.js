@@ -0,0 +1,72 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import * as jmw from '../src/index.js';
+import { createTempDir } from './helpers.js';
+
+function writePom(directory, artifactId, packaging) {
+  fs.mkdirSync(directory, { recursive: true });
+  fs.writeFileSync(path.join(directory, 'pom.xml'), `<project>
+  <artifactId>${artifactId}</artifactId>
+  <version>1.0</version>
+  <packaging>${packaging}</packaging>
+</project>
+`);
+}
+
+function createProject(t) {
+  const basePath = createTempDir(t, 'library');
+
+  writePom(path.join(basePath, 'core'), 'core', 'jar');
+  writePom(path.join(basePath, 'web'), 'web', 'war');
+
+  return {
+    basePath,
+    config: {
+      projects: {
+        shop: {
+          base_path: basePath,
+          global_modules: { core: 'modules/com/acme/core/main' }
+        }
+      },
+      restart_rules: { patterns: [] }
+    }
+  };
+}
+
+test('the package entry exports the library without running the CLI', () => {
+  for (const name of ['loadConfig', 'detectFromDir', 'decideRestart', 'deployArtifact', 'setOutputStreams']) {
+    assert.equal(typeof jmw[name], 'function', name);
+  }
+});
+
+test('detectFromDir classifies a global module', (t) => {
+  const { basePath, config } = createProject(t);
+
+  const detection = jmw.detectFromDir(path.join(basePath, 'core'), config);
+
+  assert.equal(detection.project, 'shop');
+  assert.equal(detection.module.artifactId, 'core');
+  assert.equal(detection.module.isGlobalModule, true);
+  assert.deepEqual(detection.module.deploymentPaths, ['modules/com/acme/core/main']);
+  assert.equal(detection.module.artifactName, 'core-1.0.jar');
+});
+
+test('detectFromDir classifies a normal deployment', (t) => {
+  const { basePath, config } = createProject(t);
+
+  const detection = jmw.detectFromDir(path.join(basePath, 'web'), config);
+
+  assert.equal(detection.module.artifactId, 'web');
+  assert.equal(detection.module.isGlobalModule, false);
+  assert.equal(detection.module.relativePath, 'web');
+  assert.equal(detection.module.artifactName, 'web-1.0.war');
+});
+
+test('detectFromDir rejects directories outside every project', (t) => {
+  const { config } = createProject(t);
+
+  assert.throws(() => jmw.detectFromDir(os.tmpdir(), config), /not within any configured project/);
+});
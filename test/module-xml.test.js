@@ -0,0 +1,45 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { syncModuleXml } from '../src/deploy/module-xml.js';
+import { createTempDir } from './helpers.js';
+
+const DEPLOYMENT_PATH = 'modules/com/acme/core/main';
+
+function writeJar(directory, name, mtime) {
+  fs.writeFileSync(path.join(directory, name), name);
+  fs.utimesSync(path.join(directory, name), mtime, mtime);
+}
+
+function readResourceRoots(filePath) {
+  return [...fs.readFileSync(filePath, 'utf8').matchAll(/resource-root path="([^"]+)"/g)].map((match) => match[1]);
+}
+
+test('only the configured artifacts sharing the module are resource roots', (t) => {
+  const directory = createTempDir(t, 'module-xml');
+  writeJar(directory, 'core-1.0.jar', new Date('2026-01-01T00:00:00Z'));
+  writeJar(directory, 'core-1.1.jar', new Date('2026-01-02T00:00:00Z'));
+  writeJar(directory, 'core-api-2.0.jar', new Date('2026-01-01T00:00:00Z'));
+  writeJar(directory, 'core-api-2.1.jar', new Date('2026-01-03T00:00:00Z'));
+  writeJar(directory, 'left-over.jar', new Date('2026-01-01T00:00:00Z'));
+  const moduleXml = { dependencies: [], owners: { [DEPLOYMENT_PATH]: ['core', 'core-api'] } };
+
+  const outcome = syncModuleXml(directory, DEPLOYMENT_PATH, moduleXml, { artifactName: 'core-1.1.jar' });
+
+  assert.equal(outcome.status, 'written');
+  assert.equal(outcome.name, 'com.acme.core');
+  assert.deepEqual(readResourceRoots(outcome.filePath), ['core-1.1.jar', 'core-api-2.1.jar']);
+  assert.deepEqual(outcome.skipped.sort(), ['core-1.0.jar', 'core-api-2.0.jar', 'left-over.jar']);
+});
+
+test('a module.xml that jmw did not write is left alone', (t) => {
+  const directory = createTempDir(t, 'module-xml');
+  writeJar(directory, 'core-1.1.jar', new Date());
+  fs.writeFileSync(path.join(directory, 'module.xml'), '<module name="com.acme.core"/>\n');
+
+  const outcome = syncModuleXml(directory, DEPLOYMENT_PATH, { dependencies: [] }, { artifactName: 'core-1.1.jar' });
+
+  assert.equal(outcome.status, 'hand-written');
+  assert.equal(fs.readFileSync(outcome.filePath, 'utf8'), '<module name="com.acme.core"/>\n');
+});
@@ -0,0 +1,112 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { Writable } from 'node:stream';
+import {
+  formatDetail,
+  getCommandStreams,
+  printError,
+  printInfo,
+  printWarning,
+  printSection,
+  setLogFormat,
+  setOutputFormat,
+  setOutputStreams,
+  withBufferedOutput
+} from '../src/output.js';
+
+function createCapture() {
+  const chunks = [];
+  const stream = new Writable({
+    write(chunk, encoding, callback) {
+      chunks.push(String(chunk));
+      callback();
+    }
+  });
+
+  return { stream, text: () => chunks.join('') };
+}
+
+function captureOutput(t) {
+  const stdout = createCapture();
+  const stderr = createCapture();
+  setOutputStreams({ stdout: stdout.stream, stderr: stderr.stream });
+  t.after(() => {
+    setOutputStreams();
+    setOutputFormat('text');
+    setLogFormat('text');
+  });
+
+  return { stdout, stderr };
+}
+
+test('log lines go to the configured streams', (t) => {
+  const { stdout, stderr } = captureOutput(t);
+
+  printInfo('deploying app.war');
+  printError('deploy failed');
+
+  assert.match(stdout.text(), /deploying app\.war\n$/);
+  assert.doesNotMatch(stdout.text(), /deploy failed/);
+  assert.match(stderr.text(), /deploy failed\n$/);
+});
+
+test('buffered output is written when the task settles', async (t) => {
+  const { stdout } = captureOutput(t);
+
+  await withBufferedOutput(async () => {
+    printInfo('first');
+    assert.equal(stdout.text(), '');
+    printInfo('second');
+  });
+
+  assert.match(stdout.text(), /first\n.*second\n$/s);
+});
+
+test('child process output uses the configured streams', (t) => {
+  const { stdout, stderr } = captureOutput(t);
+
+  getCommandStreams().stdout.write('cli stdout\n');
+  getCommandStreams().stderr.write('cli stderr\n');
+
+  assert.equal(stdout.text(), 'cli stdout\n');
+  assert.equal(stderr.text(), 'cli stderr\n');
+});
+
+test('child process stdout moves to stderr with JSON output', (t) => {
+  const { stdout, stderr } = captureOutput(t);
+  setOutputFormat('json');
+
+  getCommandStreams().stdout.write('cli stdout\n');
+
+  assert.equal(stdout.text(), '');
+  assert.equal(stderr.text(), 'cli stdout\n');
+});
+
+test('warnings move to stderr with JSON output instead of being dropped', (t) => {
+  const { stdout, stderr } = captureOutput(t);
+  setOutputFormat('json');
+
+  printInfo('deploying app.war');
+  printWarning('app.war is older than its sources');
+
+  assert.equal(stdout.text(), '');
+  assert.match(stderr.text(), /app\.war is older than its sources\n$/);
+});
+
+test('JSON log lines carry only the fields the caller passes', (t) => {
+  const { stdout } = captureOutput(t);
+  setLogFormat('json');
+
+  printSection('summary', [formatDetail('actions', 2), formatDetail('duration', '2.5s')], { actions: 2, duration: 2500, artifact: undefined });
+  const line = JSON.parse(stdout.text());
+
+  assert.equal(line.msg, 'summary · actions 2 · duration 2.5s');
+  assert.deepEqual({ ...line, time: undefined }, {
+    time: undefined,
+    level: 'info',
+    msg: line.msg,
+    phase: 'summary',
+    actions: 2,
+    duration: 2500
+  });
+});
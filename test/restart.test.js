@@ -0,0 +1,195 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import { RESTART_STATUSES, decideRestart, evaluateRestartDecision, matchRestartRules, parseIgnorePatterns } from '../src/build/restart.js';
+
+const restartRules = {
+  patterns: [
+    { match: 'entities/.*\\.java', reason: 'Entity class modification', severity: 'required' },
+    { match: 'EJB.*\\.java', reason: 'EJB implementation change', severity: 'recommended' },
+    { match: '**/*.properties', reason: 'Properties change', severity: 'none' },
+    { match: 'broken[', reason: 'Invalid regex, still a glob', severity: 'required' }
+  ],
+  ignore: ['target/']
+};
+
+const jarModule = { packaging: 'jar', relativePath: 'core' };
+
+const cases = [
+  {
+    name: 'WAR hot-deploys without a restart',
+    moduleInfo: { packaging: 'war' },
+    options: { modifiedFiles: ['web/entities/User.java'] },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'WAR hot-deployment'
+  },
+  {
+    name: 'global modules always require a restart',
+    moduleInfo: { packaging: 'jar', isGlobalModule: true },
+    options: { modifiedFiles: [] },
+    status: RESTART_STATUSES.REQUIRED,
+    reason: 'Global module deployment'
+  },
+  {
+    name: 'entity changes require a restart',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/src/main/java/entities/User.java'] },
+    status: RESTART_STATUSES.REQUIRED
+  },
+  {
+    name: 'EJB changes recommend a restart',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/src/main/java/EJBPcsBean.java'] },
+    status: RESTART_STATUSES.RECOMMENDED
+  },
+  {
+    name: 'the most severe rule wins when several match',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/src/main/java/EJBPcsBean.java', 'core/src/main/java/entities/Order.java'] },
+    status: RESTART_STATUSES.REQUIRED
+  },
+  {
+    name: 'severity none matches without asking for a restart',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/src/main/resources/app.properties'] },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'Restart rules matched'
+  },
+  {
+    name: 'no matching rule needs no restart',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/README.md'] },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'No critical files modified'
+  },
+  {
+    name: 'an invalid regular expression does not throw and never matches as a regex',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/broken[x'] },
+    status: RESTART_STATUSES.NOT_REQUIRED
+  },
+  {
+    name: 'files of other modules are not considered',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['web/src/main/java/entities/User.java'] },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'No files modified in target module'
+  },
+  {
+    name: 'an unreadable working tree is unknown',
+    moduleInfo: jarModule,
+    options: {},
+    status: RESTART_STATUSES.UNKNOWN
+  },
+  {
+    name: 'restart_overrides force required',
+    moduleInfo: jarModule,
+    options: { artifactName: 'EJBPcs.jar', restartOverrides: { required: ['EJB*.jar'] }, modifiedFiles: [] },
+    status: RESTART_STATUSES.REQUIRED,
+    reason: "Artifact matches restart override 'EJB*.jar'"
+  },
+  {
+    name: 'restart_overrides force not-required over the rules',
+    moduleInfo: jarModule,
+    options: {
+      artifactName: 'core.jar',
+      restartOverrides: { 'not-required': ['core*.jar'] },
+      modifiedFiles: ['core/src/main/java/entities/User.java']
+    },
+    status: RESTART_STATUSES.NOT_REQUIRED
+  },
+  {
+    name: 'restart_rules.ignore drops build output',
+    moduleInfo: jarModule,
+    options: { modifiedFiles: ['core/target/classes/entities/User.java'] },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'No files modified in target module'
+  },
+  {
+    name: '.jmwignore entries drop matching files',
+    moduleInfo: jarModule,
+    options: {
+      modifiedFiles: ['core/src/main/java/entities/User.java'],
+      ignore: parseIgnorePatterns('# generated\nentities/\n')
+    },
+    status: RESTART_STATUSES.NOT_REQUIRED,
+    reason: 'No files modified in target module'
+  },
+  {
+    name: 'EAR packaging requires a restart',
+    moduleInfo: { packaging: 'ear' },
+    options: { modifiedFiles: [] },
+    status: RESTART_STATUSES.REQUIRED,
+    reason: 'EAR deployment'
+  },
+  {
+    name: 'an .ear artifact requires a restart whatever the packaging',
+    moduleInfo: jarModule,
+    options: { artifactName: 'suite.ear', modifiedFiles: [] },
+    status: RESTART_STATUSES.REQUIRED,
+    reason: 'EAR deployment'
+  },
+  {
+    name: 'restart_overrides can opt an EAR out',
+    moduleInfo: { packaging: 'ear' },
+    options: { artifactName: 'suite.ear', restartOverrides: { 'not-required': ['suite.ear'] } },
+    status: RESTART_STATUSES.NOT_REQUIRED
+  }
+];
+
+for (const { name, moduleInfo, options, status, reason } of cases) {
+  test(`decideRestart: ${name}`, () => {
+    const decision = decideRestart(moduleInfo, restartRules, options);
+
+    assert.equal(decision.status, status);
+    if (reason) {
+      assert.equal(decision.reason, reason);
+    }
+  });
+}
+
+test('decideRestart: matched files keep their most severe rule', () => {
+  const decision = decideRestart(jarModule, restartRules, {
+    modifiedFiles: ['core/src/main/java/entities/EJBUser.java']
+  });
+
+  assert.deepEqual(decision.matches.map((match) => [match.file, match.severity]), [
+    ['core/src/main/java/entities/EJBUser.java', 'required']
+  ]);
+});
+
+test('decideRestart: without restart rules the decision is unknown', () => {
+  assert.equal(decideRestart(jarModule, null, { modifiedFiles: ['core/a.java'] }).status, RESTART_STATUSES.UNKNOWN);
+});
+
+test('evaluateRestartDecision: an EAR is decided without reading git', async () => {
+  const decision = await evaluateRestartDecision({ packaging: 'ear', path: '/nonexistent' }, restartRules, {
+    getModifiedFiles: () => {
+      throw new Error('git must not be read for an EAR');
+    }
+  });
+
+  assert.equal(decision.status, RESTART_STATUSES.REQUIRED);
+  assert.equal(decision.reason, 'EAR deployment');
+});
+
+test('matchRestartRules: each file keeps the most severe rule, the first declared on a tie', () => {
+  const patterns = [
+    { match: '**/*.java', reason: 'Java change', severity: 'none' },
+    { match: 'EJB.*\\.java', reason: 'EJB change', severity: 'recommended' },
+    { match: '.*Bean\\.java', reason: 'Bean change', severity: 'recommended' },
+    { match: 'entities/', reason: 'Entity change', severity: 'required' }
+  ];
+
+  const matches = matchRestartRules([
+    'src/entities/EJBUserBean.java',
+    'src/EJBOrderBean.java',
+    'src/Util.java',
+    'README.md'
+  ], patterns);
+
+  assert.deepEqual(matches.map((match) => [match.file, match.severity, match.reason]), [
+    ['src/entities/EJBUserBean.java', 'required', 'Entity change'],
+    ['src/EJBOrderBean.java', 'recommended', 'EJB change'],
+    ['src/Util.java', 'none', 'Java change']
+  ]);
+});
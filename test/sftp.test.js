@@ -0,0 +1,67 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import crypto from 'node:crypto';
+import fs from 'node:fs';
+import path from 'node:path';
+import { getHostKeyFingerprint, verifyHostKey } from '../src/deploy/sftp.js';
+import { createTempDir } from './helpers.js';
+
+const hostKey = Buffer.from('ssh-ed25519 host key of wildfly-1');
+const otherKey = Buffer.from('ssh-ed25519 some other key');
+
+function writeKnownHosts(t, lines) {
+  const directory = createTempDir(t, 'known-hosts');
+
+  const file = path.join(directory, 'known_hosts');
+  fs.writeFileSync(file, `${lines.join('\n')}\n`);
+  return file;
+}
+
+function hashHost(name) {
+  const salt = crypto.randomBytes(20);
+  const hash = crypto.createHmac('sha1', salt).update(name).digest('base64');
+  return `|1|${salt.toString('base64')}|${hash}`;
+}
+
+test('accepts a key listed in known_hosts', (t) => {
+  const file = writeKnownHosts(t, [
+    '# comment',
+    `other.example ssh-ed25519 ${otherKey.toString('base64')}`,
+    `wildfly-1,10.0.0.5 ssh-ed25519 ${hostKey.toString('base64')}`
+  ]);
+
+  assert.equal(verifyHostKey({ host: 'wildfly-1' }, hostKey, file), null);
+  assert.equal(verifyHostKey({ host: '10.0.0.5' }, hostKey, file), null);
+});
+
+test('matches hashed hosts and non-default ports', (t) => {
+  const file = writeKnownHosts(t, [
+    `${hashHost('wildfly-1')} ssh-ed25519 ${hostKey.toString('base64')}`,
+    `[wildfly-2]:2222 ssh-ed25519 ${hostKey.toString('base64')}`
+  ]);
+
+  assert.equal(verifyHostKey({ host: 'wildfly-1' }, hostKey, file), null);
+  assert.equal(verifyHostKey({ host: 'wildfly-2', port: 2222 }, hostKey, file), null);
+  assert.match(verifyHostKey({ host: 'wildfly-2' }, hostKey, file), /is not in/);
+});
+
+test('fails closed on unknown, changed and revoked keys', (t) => {
+  const file = writeKnownHosts(t, [
+    `wildfly-1 ssh-ed25519 ${otherKey.toString('base64')}`,
+    `@revoked wildfly-3 ssh-ed25519 ${hostKey.toString('base64')}`,
+    `wildfly-3 ssh-ed25519 ${hostKey.toString('base64')}`
+  ]);
+
+  assert.match(verifyHostKey({ host: 'wildfly-1' }, hostKey, file), /may have changed/);
+  assert.match(verifyHostKey({ host: 'unknown' }, hostKey, file), /is not in/);
+  assert.match(verifyHostKey({ host: 'wildfly-3' }, hostKey, file), /is revoked/);
+  assert.match(verifyHostKey({ host: 'wildfly-1' }, hostKey, '/nonexistent/known_hosts'), /cannot be read/);
+});
+
+test('a configured host_fingerprint replaces known_hosts', () => {
+  const fingerprint = getHostKeyFingerprint(hostKey);
+
+  assert.match(fingerprint, /^SHA256:[A-Za-z0-9+/]{43}$/);
+  assert.equal(verifyHostKey({ host: 'wildfly-1', host_fingerprint: fingerprint }, hostKey, '/nonexistent/known_hosts'), null);
+  assert.match(verifyHostKey({ host: 'wildfly-1', host_fingerprint: fingerprint }, otherKey), /does not match host_fingerprint/);
+});
@@ -0,0 +1,43 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { clearState } from '../src/state.js';
+import { createTempDir } from './helpers.js';
+
+function createStateDir(t) {
+  const stateDir = createTempDir(t, 'state');
+
+  fs.writeFileSync(path.join(stateDir, 'deploy-2026-09.log'), '{}\n');
+  fs.writeFileSync(path.join(stateDir, 'deploy-2026-10.log'), '{}\n');
+  fs.mkdirSync(path.join(stateDir, 'cache'));
+  fs.writeFileSync(path.join(stateDir, 'cache', 'modules.json'), '[]');
+  return stateDir;
+}
+
+test('clearing state keeps the audit log', (t) => {
+  const stateDir = createStateDir(t);
+
+  const removed = clearState(stateDir, { auditLog: path.join(stateDir, 'deploy-%Y-%m.log') });
+
+  assert.deepEqual(removed, [path.join(stateDir, 'cache')]);
+  assert.deepEqual(fs.readdirSync(stateDir).sort(), ['deploy-2026-09.log', 'deploy-2026-10.log']);
+});
+
+test('includeAudit removes the audit log too', (t) => {
+  const stateDir = createStateDir(t);
+
+  clearState(stateDir, { auditLog: path.join(stateDir, 'deploy-%Y-%m.log'), includeAudit: true });
+
+  assert.deepEqual(fs.readdirSync(stateDir), []);
+});
+
+test('a directory holding audit files is kept', (t) => {
+  const stateDir = createStateDir(t);
+  fs.mkdirSync(path.join(stateDir, 'audit'));
+  fs.writeFileSync(path.join(stateDir, 'audit', 'deploy-2026.log'), '{}\n');
+
+  clearState(stateDir, { auditLog: path.join(stateDir, 'audit', 'deploy-%Y.log') });
+
+  assert.deepEqual(fs.readdirSync(stateDir), ['audit']);
+});
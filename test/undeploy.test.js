@@ -0,0 +1,57 @@
+import test from 'node:test';
+import assert from 'node:assert/strict';
+import fs from 'node:fs';
+import path from 'node:path';
+import { createDeploymentResult, undeployStandaloneArtifact } from '../src/deploy/execution.js';
+import { getWildflyConfig } from '../src/deploy/wildfly.js';
+import { createTempDir, silenceOutput } from './helpers.js';
+
+function createDeployment(t, deployTimeout = '5s') {
+  const root = createTempDir(t, 'undeploy');
+  const deploymentsDir = path.join(root, 'standalone', 'deployments');
+  silenceOutput(t);
+
+  fs.mkdirSync(deploymentsDir, { recursive: true });
+  fs.writeFileSync(path.join(deploymentsDir, 'app.war'), 'war');
+  fs.writeFileSync(path.join(deploymentsDir, 'app.war.deployed'), '');
+
+  return {
+    deployedPath: path.join(deploymentsDir, 'app.war'),
+    wildflyConfig: getWildflyConfig({ wildfly_root: root, deploy_timeout: deployTimeout })
+  };
+}
+
+test('the artifact is removed only after the scanner writes .undeployed', async (t) => {
+  const { deployedPath, wildflyConfig } = createDeployment(t);
+  let artifactPresentWhenUndeployed = null;
+
+  // Stands in for the deployment scanner reacting to the removed .deployed.
+  const scanner = setInterval(() => {
+    if (!fs.existsSync(`${deployedPath}.deployed`)) {
+      clearInterval(scanner);
+      artifactPresentWhenUndeployed = fs.existsSync(deployedPath);
+      fs.writeFileSync(`${deployedPath}.undeployed`, '');
+    }
+  }, 20);
+  t.after(() => clearInterval(scanner));
+
+  const result = await undeployStandaloneArtifact('app.war', wildflyConfig, createDeploymentResult());
+
+  assert.equal(artifactPresentWhenUndeployed, true);
+  assert.deepEqual(fs.readdirSync(path.dirname(deployedPath)), []);
+  assert.deepEqual(result.actions.map((action) => [action.type, path.basename(action.path)]), [
+    ['marker_removed', 'app.war.deployed'],
+    ['file_removed', 'app.war'],
+    ['marker_removed', 'app.war.undeployed']
+  ]);
+});
+
+test('the artifact stays when the scanner never answers', async (t) => {
+  const { deployedPath, wildflyConfig } = createDeployment(t, '100ms');
+
+  await assert.rejects(
+    undeployStandaloneArtifact('app.war', wildflyConfig, createDeploymentResult()),
+    (error) => error.code === 'UNDEPLOY_TIMEOUT' && error.phase === 'undeploy'
+  );
+  assert.equal(fs.existsSync(deployedPath), true);
+});